@@ -0,0 +1,265 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+const (
+	AuthTypeOAuth2ClientCredentials string = "oauth2-client-credentials"
+	AuthTypeAWSSigV4                string = "aws-sigv4"
+	AuthTypeBearer                  string = "bearer"
+	AuthTypeBasic                   string = "basic"
+	AuthTypeAPIKey                  string = "apiKey"
+)
+
+const (
+	APIKeyInHeader string = "header"
+	APIKeyInQuery  string = "query"
+)
+
+// How the oauth2-client-credentials type sends the client credentials to the token endpoint.
+const (
+	// OAuth2ClientSecretBasic sends the credentials with HTTP Basic authentication.  This is the default.
+	OAuth2ClientSecretBasic string = "client_secret_basic"
+	// OAuth2ClientSecretPost sends the credentials in the form body, for providers that don't support Basic authentication.
+	OAuth2ClientSecretPost string = "client_secret_post"
+)
+
+type AuthInfo struct {
+	Type string `json:"type"`
+
+	// Used by the oauth2-client-credentials type.
+	TokenURL     string   `json:"tokenURL"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes"`
+	Audience     string   `json:"audience"`
+	// ClientAuthMethod is OAuth2ClientSecretBasic or OAuth2ClientSecretPost.  If empty, OAuth2ClientSecretBasic is used.
+	ClientAuthMethod string `json:"clientAuthMethod"`
+
+	// Used by the aws-sigv4 type.
+	Region          string `json:"region"`
+	Service         string `json:"service"`
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken"`
+
+	// Used by the bearer type.
+	Token string `json:"token"`
+
+	// Used by the basic type.
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Used by the apiKey type.
+	KeyName string `json:"name"`
+	In      string `json:"in"`
+	Value   string `json:"value"`
+}
+
+// Transport returns an http.RoundTripper that authenticates each request according to the auth settings,
+// before passing it to the base transport.  The settings must already be resolved, such that they don't
+// contain templates.  If base is nil, http.DefaultTransport is used.  A nil *AuthInfo returns the base transport.
+func (a *AuthInfo) Transport(base http.RoundTripper) (http.RoundTripper, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if a == nil {
+		return base, nil
+	}
+
+	for _, v := range a.templatedValues() {
+		if hasTemplate(v) {
+			return nil, errors.New("auth settings must be resolved before building a transport")
+		}
+	}
+
+	switch a.Type {
+	case AuthTypeOAuth2ClientCredentials:
+		return newOAuth2Transport(a, base), nil
+	case AuthTypeAWSSigV4:
+		return &sigV4Transport{auth: a, base: base}, nil
+	case AuthTypeBearer, AuthTypeBasic, AuthTypeAPIKey:
+		return &staticAuthTransport{auth: a, base: base}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth type: [%s]", a.Type)
+	}
+}
+
+// templatedValues returns the values that may contain templates, in the order their variables are reported.
+func (a *AuthInfo) templatedValues() []string {
+	switch a.Type {
+	case AuthTypeOAuth2ClientCredentials:
+		values := []string{a.TokenURL, a.ClientID, a.ClientSecret}
+		values = append(values, a.Scopes...)
+		return append(values, a.Audience)
+	case AuthTypeAWSSigV4:
+		return []string{a.AccessKeyID, a.SecretAccessKey, a.SessionToken}
+	case AuthTypeBearer:
+		return []string{a.Token}
+	case AuthTypeBasic:
+		return []string{a.Username, a.Password}
+	case AuthTypeAPIKey:
+		return []string{a.Value}
+	default:
+		return nil
+	}
+}
+
+// Headers returns the headers that the auth settings expand into, for the bearer, basic, and apiKey (in header) types.
+// Templates are preserved, so the result can be used in the same way as the host's Headers field.
+func (a *AuthInfo) Headers() map[string]string {
+	if a == nil {
+		return nil
+	}
+
+	switch a.Type {
+	case AuthTypeBearer:
+		return map[string]string{"Authorization": "Bearer " + a.Token}
+	case AuthTypeBasic:
+		return map[string]string{"Authorization": "Basic " + basicCredentials(a.Username, a.Password)}
+	case AuthTypeAPIKey:
+		if a.In == APIKeyInHeader || a.In == "" {
+			return map[string]string{a.KeyName: a.Value}
+		}
+	}
+	return nil
+}
+
+// QueryParameters returns the query parameters that the auth settings expand into, for the apiKey (in query) type.
+// Templates are preserved, so the result can be used in the same way as the host's QueryParameters field.
+func (a *AuthInfo) QueryParameters() map[string]string {
+	if a == nil {
+		return nil
+	}
+
+	if a.Type == AuthTypeAPIKey && a.In == APIKeyInQuery {
+		return map[string]string{a.KeyName: a.Value}
+	}
+	return nil
+}
+
+// expands reports whether the auth settings are fully expressed by their headers and query parameters.
+func (a *AuthInfo) expands() bool {
+	if a == nil {
+		return false
+	}
+
+	switch a.Type {
+	case AuthTypeBearer, AuthTypeBasic, AuthTypeAPIKey:
+		return true
+	default:
+		return false
+	}
+}
+
+// basicCredentials returns the encoded credentials for basic authentication.  When both values are
+// single variable templates, the equivalent base64 template is returned, matching the header-based form.
+func basicCredentials(username, password string) string {
+	u, uok := singleVariable(username)
+	p, pok := singleVariable(password)
+	if uok && pok {
+		return "{{base64(" + u + ":" + p + ")}}"
+	}
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+// variables returns the template variables used by auth settings that are not expanded into headers
+// or query parameters.  Variables of expanded settings are reported along with the host's headers and
+// query parameters instead, so the order is the same as the equivalent header-based form.
+func (a *AuthInfo) variables() []string {
+	if a == nil || a.expands() {
+		return nil
+	}
+
+	var results []string
+	for _, v := range a.templatedValues() {
+		results = append(results, extractVariables(v)...)
+	}
+	return results
+}
+
+func (a *AuthInfo) clone() *AuthInfo {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.Scopes = slices.Clone(a.Scopes)
+	return &c
+}
+
+// redacted returns a copy of the auth settings, with the credentials masked.
+func (a *AuthInfo) redacted() *AuthInfo {
+	if a == nil {
+		return nil
+	}
+	r := *a
+	r.ClientSecret = redactValue(r.ClientSecret)
+	r.AccessKeyID = redactValue(r.AccessKeyID)
+	r.SecretAccessKey = redactValue(r.SecretAccessKey)
+	r.SessionToken = redactValue(r.SessionToken)
+	r.Token = redactValue(r.Token)
+	r.Password = redactValue(r.Password)
+	r.Value = redactValue(r.Value)
+	return &r
+}
+
+func (a *AuthInfo) hashFields() []hashField {
+	if a == nil {
+		return nil
+	}
+
+	return []hashField{
+		{"auth.type", a.Type, legacyExtension},
+		{"auth.tokenURL", a.TokenURL, legacyExtension},
+		{"auth.clientId", a.ClientID, legacyExtension},
+		{"auth.clientSecret", a.ClientSecret, legacyExtension},
+		{"auth.scopes", a.Scopes, legacyExtension},
+		{"auth.audience", a.Audience, legacyExtension},
+		{"auth.clientAuthMethod", a.ClientAuthMethod, legacyExtension},
+		{"auth.region", a.Region, legacyExtension},
+		{"auth.service", a.Service, legacyExtension},
+		{"auth.accessKeyId", a.AccessKeyID, legacyExtension},
+		{"auth.secretAccessKey", a.SecretAccessKey, legacyExtension},
+		{"auth.sessionToken", a.SessionToken, legacyExtension},
+		{"auth.token", a.Token, legacyExtension},
+		{"auth.username", a.Username, legacyExtension},
+		{"auth.password", a.Password, legacyExtension},
+		{"auth.name", a.KeyName, legacyExtension},
+		{"auth.in", a.In, legacyExtension},
+		{"auth.value", a.Value, legacyExtension},
+	}
+}
+
+// staticAuthTransport adds the headers and query parameters of the bearer, basic, and apiKey types to each request.
+type staticAuthTransport struct {
+	auth *AuthInfo
+	base http.RoundTripper
+}
+
+func (t *staticAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the original request.
+	r := req.Clone(req.Context())
+
+	for k, v := range t.auth.Headers() {
+		r.Header.Set(k, v)
+	}
+
+	if params := t.auth.QueryParameters(); len(params) > 0 {
+		q := r.URL.Query()
+		for k, v := range params {
+			q.Set(k, v)
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+
+	return t.base.RoundTrip(r)
+}
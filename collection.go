@@ -18,3 +18,36 @@ type OptionsInfo struct {
 	EfConstruction int `json:"efConstruction"`
 	MaxLevels      int `json:"maxLevels"`
 }
+
+// Hash returns the hash of the collection, which changes whenever any of its search methods change.
+// It uses HashVersionDefault.
+func (c CollectionInfo) Hash() string {
+	return c.HashWith(HashVersionDefault)
+}
+
+func (c CollectionInfo) HashWith(version HashVersion) string {
+	methods := make(map[string]string, len(c.SearchMethods))
+	for name, sm := range c.SearchMethods {
+		methods[name] = sm.HashWith(version)
+	}
+
+	return computeHash(version, []hashField{
+		{"searchMethods", methods, legacyExtension},
+	})
+}
+
+// Hash returns the hash of the search method, covering the embedder and the index options.
+// When it changes, the collection must be re-embedded or reindexed for that search method.
+// It uses HashVersionDefault.
+func (s SearchMethodInfo) Hash() string {
+	return s.HashWith(HashVersionDefault)
+}
+
+func (s SearchMethodInfo) HashWith(version HashVersion) string {
+	return computeHash(version, []hashField{
+		{"embedder", s.Embedder, legacyExtension},
+		{"index.type", s.Index.Type, legacyExtension},
+		{"index.options.efConstruction", s.Index.Options.EfConstruction, legacyExtension},
+		{"index.options.maxLevels", s.Index.Options.MaxLevels, legacyExtension},
+	})
+}
@@ -4,11 +4,7 @@
 
 package manifest
 
-import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-)
+import "log/slog"
 
 const (
 	HostTypeDgraph string = "dgraph"
@@ -34,14 +30,86 @@ func (h DgraphHostInfo) GetVariables() []string {
 }
 
 func (h DgraphHostInfo) Hash() string {
-	// Concatenate the attributes into a single string
-	data := fmt.Sprintf("%v|%v|%v", h.Name, h.Type, h.GrpcTarget)
+	return h.HashWith(HashVersionLegacy)
+}
+
+func (h DgraphHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the key masked.
+func (h DgraphHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the key masked.
+func (h DgraphHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h DgraphHostInfo) redacted() HostInfo {
+	h.Key = redactValue(h.Key)
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h DgraphHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h DgraphHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if h.GrpcTarget == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "grpcTarget"),
+			Message:  "grpcTarget is required",
+		})
+	}
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a copy of the host.
+func (h DgraphHostInfo) Clone() HostInfo {
+	return h
+}
 
-	// Compute the SHA-256 hash
-	hash := sha256.Sum256([]byte(data))
+// Equal reports whether the other host is a dgraph host with the same settings.
+func (h DgraphHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
 
-	// Convert the hash to a hexadecimal string
-	hashStr := hex.EncodeToString(hash[:])
+func (h DgraphHostInfo) secretFields() []secretField {
+	return []secretField{{[]string{"key"}, h.Key, "key", "Dgraph key"}}
+}
+
+func (h DgraphHostInfo) destinations() destinationList {
+	var l destinationList
+	l.addGRPCTarget("grpcTarget", h.GrpcTarget)
+	return l
+}
+
+func (h DgraphHostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h DgraphHostInfo) hostURL() string {
+	return h.GrpcTarget
+}
+
+func (h DgraphHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
 
-	return hashStr
+func (h DgraphHostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyPositional},
+		// NOTE: The legacy Dgraph host hash included the Type field as written, which may be empty, while the
+		// canonical hash uses the resolved host type.  Each encoding uses only one of these two entries.
+		{"type", h.Type, legacyOnly},
+		{"type", h.HostType(), legacyOmitted},
+		{"grpcTarget", h.GrpcTarget, legacyPositional},
+		// NOTE: The Key field was not included in the legacy Dgraph host hash.
+		{"key", h.Key, legacyOmitted},
+	}
 }
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Severity is the severity of a diagnostic, ordered from the most severe to the least severe.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON reads a severity from its name.  An empty string is read as a warning.
+func (s *Severity) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	switch name {
+	case "error":
+		*s = SeverityError
+	case "warning", "":
+		*s = SeverityWarning
+	case "info":
+		*s = SeverityInfo
+	default:
+		return fmt.Errorf("unknown severity: [%s]", name)
+	}
+	return nil
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Diagnostic is a single finding about a manifest.
+type Diagnostic struct {
+	Severity Severity
+	// Path is a JSON pointer to the value the diagnostic is about, such as "/hosts/my-api/tls".
+	Path    string
+	Message string
+	// Rule is the ID of the lint or policy rule that produced the diagnostic, if any.
+	Rule string
+	// Fix is a suggested fix for the diagnostic, if one can be made automatically.
+	Fix *Fix
+}
+
+func (d Diagnostic) String() string {
+	msg := d.Message
+	if d.Rule != "" {
+		msg += " (" + d.Rule + ")"
+	}
+	if d.Path == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", d.Severity, d.Path, msg)
+}
+
+// ValidationError is returned by ValidateManifest when the manifest matches the schema,
+// but has one or more error diagnostics.
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		msgs[i] = d.String()
+	}
+	return "failed to validate manifest: " + strings.Join(msgs, "; ")
+}
+
+// jsonPointer builds a JSON pointer from the given reference tokens, escaping them as needed.
+func jsonPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteByte('/')
+		t = strings.ReplaceAll(t, "~", "~0")
+		t = strings.ReplaceAll(t, "/", "~1")
+		sb.WriteString(t)
+	}
+	return sb.String()
+}
+
+func hasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
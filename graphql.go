@@ -0,0 +1,213 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	HostTypeGraphQL string = "graphql"
+)
+
+const (
+	// GraphQLPersistedQueriesAutomatic sends a hash of each query, and sends the full query
+	// only when the server doesn't recognize the hash, as with Apollo automatic persisted queries.
+	GraphQLPersistedQueriesAutomatic string = "automatic"
+
+	// GraphQLPersistedQueriesRequired sends only the hashes of queries, which the server must
+	// already have registered.
+	GraphQLPersistedQueriesRequired string = "required"
+)
+
+// GraphQLSchemaFileExtensions are the file extensions allowed for a local schema file.  A ".json" file
+// contains an introspection result, and the others contain a schema in the GraphQL schema definition language.
+var GraphQLSchemaFileExtensions = []string{".graphql", ".graphqls", ".gql", ".json"}
+
+// GraphQLHostInfo is a host for a GraphQL API.  It has the same settings as an http host, except that
+// the endpoint is always used instead of a base URL, along with settings that are specific to GraphQL.
+type GraphQLHostInfo struct {
+	HTTPHostInfo
+
+	// SubscriptionURL is the ws:// or wss:// URL used for subscriptions, if different from the endpoint.
+	SubscriptionURL string `json:"subscriptionUrl,omitempty"`
+
+	// PersistedQueries is one of the GraphQLPersistedQueries constants, or empty to send full queries.
+	PersistedQueries string `json:"persistedQueries,omitempty"`
+
+	// SchemaPath is the path of a local schema file, relative to the project.  When set, tools
+	// use the file instead of introspecting the endpoint.
+	SchemaPath string `json:"schemaPath,omitempty"`
+}
+
+func (GraphQLHostInfo) HostType() string {
+	return HostTypeGraphQL
+}
+
+func (h GraphQLHostInfo) GetVariables() []string {
+	vars := h.HTTPHostInfo.GetVariables()
+	for _, v := range extractVariables(h.SubscriptionURL) {
+		if !slices.Contains(vars, v) {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h GraphQLHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h GraphQLHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with header values, query parameters, and credentials masked.
+// Templates are kept visible.
+func (h GraphQLHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with header values, query parameters, and credentials masked.
+func (h GraphQLHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h GraphQLHostInfo) redacted() HostInfo {
+	h.HTTPHostInfo = h.HTTPHostInfo.redacted().(HTTPHostInfo)
+	h.SubscriptionURL = redactURL(h.SubscriptionURL, true)
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h GraphQLHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h GraphQLHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	name := h.Name
+
+	var diags []Diagnostic
+	if h.Endpoint == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name, "endpoint"),
+			Message:  "endpoint is required",
+		})
+	}
+	if h.BaseURL != "" && h.BaseURL != h.Endpoint {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name, "baseUrl"),
+			Message:  "graphql hosts use an endpoint, not a baseUrl",
+		})
+	}
+
+	if h.SubscriptionURL != "" && !strings.HasPrefix(h.SubscriptionURL, "ws://") && !strings.HasPrefix(h.SubscriptionURL, "wss://") {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name, "subscriptionUrl"),
+			Message:  "subscriptionUrl must be a ws:// or wss:// URL",
+		})
+	}
+
+	switch h.PersistedQueries {
+	case "", GraphQLPersistedQueriesAutomatic, GraphQLPersistedQueriesRequired:
+	default:
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name, "persistedQueries"),
+			Message:  fmt.Sprintf("unknown persisted query mode: %s", h.PersistedQueries),
+		})
+	}
+
+	if h.SchemaPath != "" {
+		if msg := validateSchemaPath(h.SchemaPath); msg != "" {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("hosts", name, "schemaPath"),
+				Message:  msg,
+			})
+		}
+	}
+
+	diags = append(diags, h.HTTPHostInfo.validateSettings(opts)...)
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// validateSchemaPath returns a message describing why the schema path is not allowed, or an empty string.
+func validateSchemaPath(path string) string {
+	if msg := validateProjectPath("schemaPath", path); msg != "" {
+		return msg
+	}
+	if !slices.Contains(GraphQLSchemaFileExtensions, strings.ToLower(filepath.Ext(path))) {
+		return fmt.Sprintf("schemaPath must have one of the extensions %s", strings.Join(GraphQLSchemaFileExtensions, ", "))
+	}
+	return ""
+}
+
+// Clone returns a deep copy of the host.
+func (h GraphQLHostInfo) Clone() HostInfo {
+	h.HTTPHostInfo = h.HTTPHostInfo.Clone().(HTTPHostInfo)
+	return h
+}
+
+// Equal reports whether the other host is a graphql host with the same settings.
+func (h GraphQLHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h GraphQLHostInfo) secretFields() []secretField {
+	fields := h.HTTPHostInfo.secretFields()
+	return append(fields, urlSecretFields([]string{"subscriptionUrl"}, h.SubscriptionURL)...)
+}
+
+func (h GraphQLHostInfo) destinations() destinationList {
+	l := h.HTTPHostInfo.destinations()
+	if h.SubscriptionURL == "" {
+		return l
+	}
+	d, err := urlDestination("subscriptionUrl", h.SubscriptionURL)
+	if err != nil {
+		l.fail("subscriptionUrl", err)
+		return l
+	}
+	// WebSocket connections start as http requests, so ws and wss are checked as http and https.
+	d.scheme = strings.Replace(d.scheme, "ws", "http", 1)
+	l.add(d)
+	return l
+}
+
+func (h GraphQLHostInfo) tlsInfo() *TLSInfo {
+	return h.TLS
+}
+
+func (h GraphQLHostInfo) hostURL() string {
+	return h.Endpoint
+}
+
+func (h GraphQLHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return h.HTTPHostInfo, true
+}
+
+func (h GraphQLHostInfo) hashFields() []hashField {
+	// The http settings are hashed as for http hosts, with the graphql host type.
+	fields := h.HTTPHostInfo.hashFields()
+	for i := range fields {
+		if fields[i].name == "type" {
+			fields[i].value = h.HostType()
+		}
+	}
+	return append(fields,
+		hashField{"subscriptionUrl", h.SubscriptionURL, legacyExtension},
+		hashField{"persistedQueries", h.PersistedQueries, legacyExtension},
+		hashField{"schemaPath", h.SchemaPath, legacyExtension},
+	)
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+const (
+	HostTypeGRPC string = "grpc"
+)
+
+// GRPCHostInfo is a host for a generic gRPC service.  Connections use TLS unless Plaintext is set.
+type GRPCHostInfo struct {
+	Name       string `json:"-"`
+	Type       string `json:"type"`
+	GrpcTarget string `json:"grpcTarget"`
+
+	// Plaintext disables TLS, such as for a service on the local network.
+	Plaintext bool     `json:"plaintext,omitempty"`
+	TLS       *TLSInfo `json:"tls,omitempty"`
+
+	// Metadata is sent with each call.  Values can contain templates, the same as HTTP headers.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Service and Method are the defaults used when a function doesn't name them,
+	// such as "inference.ModelService" and "Predict".
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+func (h GRPCHostInfo) HostName() string {
+	return h.Name
+}
+
+func (GRPCHostInfo) HostType() string {
+	return HostTypeGRPC
+}
+
+func (h GRPCHostInfo) GetVariables() []string {
+	values := make([]string, 0, len(h.Metadata)+3)
+	for _, k := range sortedKeys(h.Metadata) {
+		values = append(values, h.Metadata[k])
+	}
+	values = append(values, h.TLS.templatedValues()...)
+	return extractAllVariables(values...)
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h GRPCHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h GRPCHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with metadata values and the TLS client key masked.
+// Templates are kept visible.
+func (h GRPCHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with metadata values and the TLS client key masked.
+func (h GRPCHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h GRPCHostInfo) redacted() HostInfo {
+	h.Metadata = redactMap(h.Metadata)
+	h.TLS = h.TLS.redacted()
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h GRPCHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h GRPCHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if h.GrpcTarget == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "grpcTarget"),
+			Message:  "grpcTarget is required",
+		})
+	}
+
+	if h.Plaintext && h.TLS != nil {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "tls"),
+			Message:  "tls settings can't be used with plaintext connections",
+		})
+	}
+
+	for _, k := range sortedKeys(h.Metadata) {
+		// gRPC metadata keys are lowercase, and the grpc- prefix is reserved for gRPC itself.
+		if k != strings.ToLower(k) || strings.HasPrefix(k, "grpc-") {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("hosts", h.Name, "metadata", k),
+				Message:  fmt.Sprintf("metadata key %s must be lowercase, and must not start with grpc-", k),
+			})
+		}
+	}
+
+	if h.Method != "" && h.Service == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "method"),
+			Message:  "method requires a service",
+		})
+	}
+
+	diags = append(diags, h.TLS.validate(jsonPointer("hosts", h.Name, "tls"), opts)...)
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a deep copy of the host.
+func (h GRPCHostInfo) Clone() HostInfo {
+	h.Metadata = maps.Clone(h.Metadata)
+	h.TLS = h.TLS.clone()
+	return h
+}
+
+// Equal reports whether the other host is a grpc host with the same settings.
+func (h GRPCHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+// FullMethod returns the default method in the form used by gRPC, such as "/inference.ModelService/Predict",
+// or an empty string if the host doesn't have a default service and method.
+func (h GRPCHostInfo) FullMethod() string {
+	if h.Service == "" || h.Method == "" {
+		return ""
+	}
+	return "/" + h.Service + "/" + h.Method
+}
+
+func (h GRPCHostInfo) secretFields() []secretField {
+	var fields []secretField
+	for _, k := range sortedKeys(h.Metadata) {
+		fields = append(fields, namedSecretField([]string{"metadata", k}, k, h.Metadata[k]))
+	}
+	return append(fields, h.TLS.secretFields()...)
+}
+
+func (h GRPCHostInfo) destinations() destinationList {
+	var l destinationList
+	l.addGRPCTarget("grpcTarget", h.GrpcTarget)
+	return l
+}
+
+func (h GRPCHostInfo) tlsInfo() *TLSInfo {
+	return h.TLS
+}
+
+func (h GRPCHostInfo) hostURL() string {
+	return h.GrpcTarget
+}
+
+func (h GRPCHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+func (h GRPCHostInfo) hashFields() []hashField {
+	fields := []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"grpcTarget", h.GrpcTarget, legacyExtension},
+		{"metadata", h.Metadata, legacyExtension},
+		{"plaintext", h.Plaintext, legacyExtension},
+		{"service", h.Service, legacyExtension},
+		{"method", h.Method, legacyExtension},
+	}
+	return append(fields, h.TLS.hashFields()...)
+}
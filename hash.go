@@ -0,0 +1,155 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// HashVersion identifies the encoding used to compute a hash.  Hashing with an unknown version
+// returns an empty string, which never matches a computed hash.
+type HashVersion int
+
+const (
+	// HashVersionLegacy reproduces the hashes computed by earlier versions of this module,
+	// so that existing manifests keep the same hash values.  Fields that were added after
+	// the legacy encoding was defined are appended only when they are set.
+	HashVersionLegacy HashVersion = 1
+
+	// HashVersionCanonical hashes a canonical encoding of every declared field.
+	// Fields are sorted by name, values are JSON encoded, and unset fields are omitted,
+	// so adding a new field does not change the hash of items that don't use it.
+	HashVersionCanonical HashVersion = 2
+
+	// HashVersionDefault is the version used by Hash for types that didn't have a hash before versions
+	// were introduced.  Types that did, such as models and HTTP, PostgreSQL and Dgraph hosts, use
+	// HashVersionLegacy instead, so that their existing hashes don't change.
+	HashVersionDefault = HashVersionCanonical
+)
+
+// legacyEncoding describes how a field is written by HashVersionLegacy.
+type legacyEncoding int
+
+const (
+	// legacyExtension fields were added after the legacy encoding was defined.
+	// They are appended as name=value, and only when they are set.
+	legacyExtension legacyEncoding = iota
+
+	// legacyPositional fields are always written, in declaration order.
+	legacyPositional
+
+	// legacyPositionalIfSet fields are written in declaration order, only when they are set.
+	legacyPositionalIfSet
+
+	// legacyOmitted fields were not part of the legacy encoding, and are not written.
+	legacyOmitted
+
+	// legacyOnly fields are always written by the legacy encoding, in declaration order,
+	// but are not part of the canonical encoding.  They preserve legacy values that the
+	// canonical encoding represents differently.
+	legacyOnly
+)
+
+// hashField is a single declared field that participates in a hash.
+type hashField struct {
+	name   string
+	value  any
+	legacy legacyEncoding
+}
+
+// computeHash returns the hex encoded SHA-256 hash of the fields, using the given encoding version,
+// or an empty string if the version is unknown.
+func computeHash(version HashVersion, fields []hashField) string {
+	var data string
+	switch version {
+	case HashVersionLegacy:
+		data = encodeLegacy(fields)
+	case HashVersionCanonical:
+		data = encodeCanonical(fields)
+	default:
+		return ""
+	}
+
+	// Compute the SHA-256 hash
+	hash := sha256.Sum256([]byte(data))
+
+	// Convert the hash to a hexadecimal string
+	return hex.EncodeToString(hash[:])
+}
+
+func encodeLegacy(fields []hashField) string {
+	parts := make([]string, 0, len(fields))
+	for _, f := range fields {
+		switch f.legacy {
+		case legacyPositional, legacyOnly:
+			parts = append(parts, fmt.Sprintf("%v", f.value))
+		case legacyPositionalIfSet:
+			if !isZeroHashValue(f.value) {
+				parts = append(parts, fmt.Sprintf("%v", f.value))
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if f.legacy == legacyExtension && !isZeroHashValue(f.value) {
+			parts = append(parts, f.name+"="+canonicalValue(f.value))
+		}
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func encodeCanonical(fields []hashField) string {
+	sorted := make([]hashField, 0, len(fields))
+	for _, f := range fields {
+		if f.legacy != legacyOnly && !isZeroHashValue(f.value) {
+			sorted = append(sorted, f)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].name < sorted[j].name
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "v%d\n", HashVersionCanonical)
+	for _, f := range sorted {
+		sb.WriteString(f.name)
+		sb.WriteByte('=')
+		sb.WriteString(canonicalValue(f.value))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// canonicalValue returns the JSON encoding of the value.  Map keys are sorted by the encoder,
+// so the result is stable for equal values.
+func canonicalValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// Hashed fields are plain data, so this should never happen.
+		panic(fmt.Sprintf("failed to encode hash field: %v", err))
+	}
+	return string(b)
+}
+
+// isZeroHashValue reports whether the value is unset.  Empty maps and slices are treated as unset,
+// the same as nil ones.
+func isZeroHashValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
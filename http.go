@@ -5,10 +5,14 @@
 package manifest
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"crypto/tls"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 const (
@@ -26,6 +30,12 @@ type HTTPHostInfo struct {
 	BaseURL         string            `json:"baseURL"`
 	Headers         map[string]string `json:"headers"`
 	QueryParameters map[string]string `json:"queryParameters"`
+	Timeout         Duration          `json:"timeout,omitempty"`
+	Retry           *RetryInfo        `json:"retry"`
+	RateLimit       *RateLimitInfo    `json:"rateLimit"`
+	TLS             *TLSInfo          `json:"tls"`
+	Auth            *AuthInfo         `json:"auth"`
+	Proxy           *ProxyInfo        `json:"proxy"`
 }
 
 func (h HTTPHostInfo) HostName() string {
@@ -41,8 +51,7 @@ func (h HTTPHostInfo) GetVariables() []string {
 	set := make(map[string]bool, cap)
 	results := make([]string, 0, cap)
 
-	for _, header := range h.Headers {
-		vars := extractVariables(header)
+	add := func(vars []string) {
 		for _, v := range vars {
 			if _, ok := set[v]; !ok {
 				set[v] = true
@@ -51,31 +60,277 @@ func (h HTTPHostInfo) GetVariables() []string {
 		}
 	}
 
-	for _, v := range h.QueryParameters {
-		vars := extractVariables(v)
-		for _, v := range vars {
-			if _, ok := set[v]; !ok {
-				set[v] = true
-				results = append(results, v)
+	for _, header := range h.EffectiveHeaders() {
+		add(extractVariables(header))
+	}
+
+	for _, v := range h.EffectiveQueryParameters() {
+		add(extractVariables(v))
+	}
+
+	add(h.TLS.variables())
+	add(h.Auth.variables())
+	add(h.Proxy.variables())
+
+	return results
+}
+
+func (h HTTPHostInfo) Hash() string {
+	return h.HashWith(HashVersionLegacy)
+}
+
+func (h HTTPHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with header values, query parameters, and credentials masked.
+// Templates are kept visible.
+func (h HTTPHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with header values, query parameters, and credentials masked.
+func (h HTTPHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h HTTPHostInfo) redacted() HostInfo {
+	h.Endpoint = redactURL(h.Endpoint, true)
+	h.BaseURL = redactURL(h.BaseURL, true)
+	h.Headers = redactMap(h.Headers)
+	h.QueryParameters = redactMap(h.QueryParameters)
+	h.TLS = h.TLS.redacted()
+	h.Auth = h.Auth.redacted()
+	h.Proxy = h.Proxy.redacted()
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h HTTPHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h HTTPHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	name := h.Name
+
+	var diags []Diagnostic
+	switch {
+	case h.Endpoint == "" && h.BaseURL == "":
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name),
+			Message:  ErrNoHostURL.Error(),
+		})
+	case h.BaseURL != "" && h.BaseURL != h.Endpoint && !strings.HasSuffix(h.BaseURL, "/"):
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", name, "baseUrl"),
+			Message:  ErrMissingTrailingSlash.Error(),
+		})
+	}
+
+	diags = append(diags, h.validateSettings(opts)...)
+	diags = append(diags, findSecrets(h, opts)...)
+	return diags
+}
+
+// validateSettings checks the settings other than the endpoint and baseURL, which are shared with graphql hosts.
+func (h HTTPHostInfo) validateSettings(opts ValidationOptions) []Diagnostic {
+	name := h.Name
+
+	diags := h.TLS.validate(jsonPointer("hosts", name, "tls"), opts)
+
+	for _, header := range sortedKeys(h.Auth.Headers()) {
+		for _, existing := range sortedKeys(h.Headers) {
+			if strings.EqualFold(header, existing) {
+				diags = append(diags, Diagnostic{
+					Severity: SeverityWarning,
+					Path:     jsonPointer("hosts", name, "headers", existing),
+					Message:  fmt.Sprintf("header %s is overridden by the %s auth settings", existing, h.Auth.Type),
+				})
 			}
 		}
 	}
 
+	for _, param := range sortedKeys(h.Auth.QueryParameters()) {
+		if _, ok := h.QueryParameters[param]; ok {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityWarning,
+				Path:     jsonPointer("hosts", name, "queryParameters", param),
+				Message:  fmt.Sprintf("query parameter %s is overridden by the %s auth settings", param, h.Auth.Type),
+			})
+		}
+	}
+
+	return diags
+}
+
+// Clone returns a deep copy of the host.
+func (h HTTPHostInfo) Clone() HostInfo {
+	h.Headers = maps.Clone(h.Headers)
+	h.QueryParameters = maps.Clone(h.QueryParameters)
+	h.Retry = h.Retry.clone()
+	h.RateLimit = h.RateLimit.clone()
+	h.TLS = h.TLS.clone()
+	h.Auth = h.Auth.clone()
+	h.Proxy = h.Proxy.clone()
+	return h
+}
+
+// Equal reports whether the other host is an http host with the same settings.
+func (h HTTPHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h HTTPHostInfo) secretFields() []secretField {
+	var fields []secretField
+	for _, k := range sortedKeys(h.Headers) {
+		fields = append(fields, namedSecretField([]string{"headers", k}, k, h.Headers[k]))
+	}
+	for _, k := range sortedKeys(h.QueryParameters) {
+		fields = append(fields, namedSecretField([]string{"queryParameters", k}, k, h.QueryParameters[k]))
+	}
+	fields = append(fields, urlSecretFields([]string{"endpoint"}, h.Endpoint)...)
+	if h.BaseURL != h.Endpoint {
+		fields = append(fields, urlSecretFields([]string{"baseUrl"}, h.BaseURL)...)
+	}
+	if a := h.Auth; a != nil {
+		fields = append(fields,
+			secretField{[]string{"auth", "token"}, a.Token, "token", "token"},
+			secretField{[]string{"auth", "password"}, a.Password, "password", "password"},
+			secretField{[]string{"auth", "value"}, a.Value, a.KeyName, "API key"},
+			secretField{[]string{"auth", "clientSecret"}, a.ClientSecret, "client secret", "client secret"},
+			secretField{[]string{"auth", "accessKeyId"}, a.AccessKeyID, "access key id", ""},
+			secretField{[]string{"auth", "secretAccessKey"}, a.SecretAccessKey, "secret access key", "secret access key"},
+			secretField{[]string{"auth", "sessionToken"}, a.SessionToken, "session token", "session token"},
+		)
+	}
+	fields = append(fields, h.TLS.secretFields()...)
+	if p := h.Proxy; p != nil {
+		fields = append(fields, secretField{[]string{"proxy", "password"}, p.Password, "proxy password", "proxy password"})
+		fields = append(fields, urlSecretFields([]string{"proxy", "url"}, p.URL)...)
+	}
+	return fields
+}
+
+func (h HTTPHostInfo) destinations() destinationList {
+	var l destinationList
+	l.addURL("endpoint", h.Endpoint)
+	if h.BaseURL != h.Endpoint {
+		l.addURL("baseUrl", h.BaseURL)
+	}
+	if h.Auth != nil {
+		l.addURL("auth/tokenURL", h.Auth.TokenURL)
+	}
+	if h.Proxy != nil && h.Proxy.URL != "" {
+		// The proxy's scheme doesn't determine how requests to the host are protected.
+		l.addServiceURL("proxy/url", h.Proxy.URL, "")
+	}
+	return l
+}
+
+func (h HTTPHostInfo) tlsInfo() *TLSInfo {
+	return h.TLS
+}
+
+func (h HTTPHostInfo) hostURL() string {
+	if h.Endpoint != "" {
+		return h.Endpoint
+	}
+	return h.BaseURL
+}
+
+func (h HTTPHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return h, true
+}
+
+func (h HTTPHostInfo) hashFields() []hashField {
+	fields := []hashField{
+		{"name", h.Name, legacyPositional},
+		// NOTE: The Type field was not included in the legacy HTTP host hash, for backwards compatibility.
+		{"type", h.HostType(), legacyOmitted},
+		{"endpoint", h.Endpoint, legacyPositional},
+		{"baseURL", h.BaseURL, legacyPositional},
+		{"headers", h.Headers, legacyPositional},
+		{"queryParameters", h.QueryParameters, legacyPositional},
+		{"timeout", h.Timeout, legacyExtension},
+	}
+	fields = append(fields, h.Retry.hashFields()...)
+	fields = append(fields, h.RateLimit.hashFields()...)
+	fields = append(fields, h.TLS.hashFields()...)
+	fields = append(fields, h.Auth.hashFields()...)
+	fields = append(fields, h.Proxy.hashFields()...)
+	return fields
+}
+
+// EffectiveHeaders returns the host's headers, including any headers that the auth settings expand into.
+// Headers from the auth settings take precedence over headers with the same name.
+func (h HTTPHostInfo) EffectiveHeaders() map[string]string {
+	return mergeMaps(h.Headers, h.Auth.Headers(), true)
+}
+
+// EffectiveQueryParameters returns the host's query parameters, including any query parameters that
+// the auth settings expand into.  Query parameters from the auth settings take precedence over
+// query parameters with the same name.
+func (h HTTPHostInfo) EffectiveQueryParameters() map[string]string {
+	return mergeMaps(h.QueryParameters, h.Auth.QueryParameters(), false)
+}
+
+// mergeMaps returns a new map with the overrides applied to the base map.
+// If foldCase is true, keys that differ only by case are treated as the same key, as with header names.
+func mergeMaps(base, overrides map[string]string, foldCase bool) map[string]string {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	results := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		results[k] = v
+	}
+	if foldCase {
+		for k := range base {
+			for o := range overrides {
+				if k != o && strings.EqualFold(k, o) {
+					delete(results, k)
+				}
+			}
+		}
+	}
+	for k, v := range overrides {
+		results[k] = v
+	}
 	return results
 }
 
-func (h HTTPHostInfo) Hash() string {
-	// Concatenate the attributes into a single string
-	// NOTE: The Type field is not included in the HTTP host hash, for backwards compatibility.  It should be include for other host types.
-	data := fmt.Sprintf("%v|%v|%v|%v|%v", h.Name, h.Endpoint, h.BaseURL, h.Headers, h.QueryParameters)
+// TLSConfig builds a *tls.Config from the host's TLS settings, which must already be resolved.
+// It returns nil if the host has no TLS settings.
+func (h HTTPHostInfo) TLSConfig() (*tls.Config, error) {
+	return h.TLS.Config()
+}
+
+// Transport returns an http.RoundTripper that applies the host's auth settings, which must already be resolved.
+// If base is nil, http.DefaultTransport is used.  It returns the base transport if the host has no auth settings.
+func (h HTTPHostInfo) Transport(base http.RoundTripper) (http.RoundTripper, error) {
+	return h.Auth.Transport(base)
+}
 
-	// Compute the SHA-256 hash
-	hash := sha256.Sum256([]byte(data))
+// ProxyFunc returns a function for use as http.Transport.Proxy, based on the host's proxy settings,
+// which must already be resolved.  It returns nil if the host has no proxy settings.
+func (h HTTPHostInfo) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	return h.Proxy.ProxyFunc()
+}
 
-	// Convert the hash to a hexadecimal string
-	hashStr := hex.EncodeToString(hash[:])
+func hasTemplate(s string) bool {
+	return templateRegex.MatchString(s)
+}
 
-	return hashStr
+// singleVariable returns the variable name if the string is exactly one variable template, such as "{{API_KEY}}".
+func singleVariable(s string) (string, bool) {
+	match := templateRegex.FindStringSubmatchIndex(s)
+	if match == nil || match[0] != 0 || match[1] != len(s) || match[6] < 0 {
+		return "", false
+	}
+	return s[match[6]:match[7]], true
 }
 
 func extractVariables(s string) []string {
@@ -95,3 +350,18 @@ func extractVariables(s string) []string {
 
 	return results
 }
+
+// extractAllVariables returns the variables used in any of the values, in order of first use, without duplicates.
+func extractAllVariables(values ...string) []string {
+	set := make(map[string]bool)
+	results := []string{}
+	for _, s := range values {
+		for _, v := range extractVariables(s) {
+			if !set[v] {
+				set[v] = true
+				results = append(results, v)
+			}
+		}
+	}
+	return results
+}
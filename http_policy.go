@@ -0,0 +1,117 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// DefaultRetryOn lists the status codes that are retried when a retry policy doesn't specify retryOn.
+var DefaultRetryOn = []int{429, 502, 503, 504}
+
+type RetryInfo struct {
+	MaxAttempts int          `json:"maxAttempts"`
+	Backoff     *BackoffInfo `json:"backoff"`
+	RetryOn     []int        `json:"retryOn"`
+}
+
+type BackoffInfo struct {
+	Initial    Duration `json:"initial"`
+	Max        Duration `json:"max"`
+	Multiplier float64  `json:"multiplier"`
+}
+
+type RateLimitInfo struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// ShouldRetry reports whether a response with the given status code should be retried.
+// It does not consider the number of attempts already made.
+func (r *RetryInfo) ShouldRetry(statusCode int) bool {
+	if r == nil {
+		return false
+	}
+	if len(r.RetryOn) == 0 {
+		return slices.Contains(DefaultRetryOn, statusCode)
+	}
+	return slices.Contains(r.RetryOn, statusCode)
+}
+
+// Delay returns the time to wait before the given retry attempt, where 1 is the first retry.
+// Without a backoff configuration, retries are made immediately.
+func (r *RetryInfo) Delay(attempt int) time.Duration {
+	if r == nil || r.Backoff == nil || attempt < 1 {
+		return 0
+	}
+
+	b := r.Backoff
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		return time.Duration(b.Max)
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+func (r *RetryInfo) clone() *RetryInfo {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	if r.Backoff != nil {
+		b := *r.Backoff
+		c.Backoff = &b
+	}
+	c.RetryOn = slices.Clone(r.RetryOn)
+	return &c
+}
+
+func (r *RetryInfo) hashFields() []hashField {
+	if r == nil {
+		return nil
+	}
+
+	fields := []hashField{
+		{"retry.maxAttempts", r.MaxAttempts, legacyExtension},
+		{"retry.retryOn", r.RetryOn, legacyExtension},
+	}
+	if r.Backoff != nil {
+		fields = append(fields,
+			hashField{"retry.backoff.initial", r.Backoff.Initial, legacyExtension},
+			hashField{"retry.backoff.max", r.Backoff.Max, legacyExtension},
+			hashField{"retry.backoff.multiplier", r.Backoff.Multiplier, legacyExtension},
+		)
+	}
+	return fields
+}
+
+func (r *RateLimitInfo) clone() *RateLimitInfo {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	return &c
+}
+
+func (r *RateLimitInfo) hashFields() []hashField {
+	if r == nil {
+		return nil
+	}
+
+	return []hashField{
+		{"rateLimit.requestsPerSecond", r.RequestsPerSecond, legacyExtension},
+		{"rateLimit.burst", r.Burst, legacyExtension},
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+)
+
+const (
+	HostTypeKafka string = "kafka"
+)
+
+const (
+	KafkaSASLPlain       string = "PLAIN"
+	KafkaSASLScramSHA256 string = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 string = "SCRAM-SHA-512"
+)
+
+// KafkaSASLMechanisms are the supported SASL mechanisms.
+var KafkaSASLMechanisms = []string{KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512}
+
+// KafkaHostInfo is a host for an Apache Kafka cluster, or a service that is compatible with Kafka.
+type KafkaHostInfo struct {
+	Name string `json:"-"`
+	Type string `json:"type"`
+
+	// Brokers lists the host and port of one or more brokers, used to discover the rest of the cluster.
+	Brokers []string `json:"brokers"`
+
+	SASL *KafkaSASLInfo `json:"sasl,omitempty"`
+
+	// TLS enables TLS for connections to the brokers.  An empty object uses the default TLS settings.
+	// If not set, connections are not encrypted.
+	TLS *TLSInfo `json:"tls,omitempty"`
+
+	// Topic is the default topic, used when a function doesn't name one.
+	Topic string `json:"topic,omitempty"`
+}
+
+// KafkaSASLInfo is the SASL authentication used for connections to the brokers.
+type KafkaSASLInfo struct {
+	Mechanism string `json:"mechanism"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+}
+
+func (h KafkaHostInfo) HostName() string {
+	return h.Name
+}
+
+func (KafkaHostInfo) HostType() string {
+	return HostTypeKafka
+}
+
+func (h KafkaHostInfo) GetVariables() []string {
+	var values []string
+	if h.SASL != nil {
+		values = append(values, h.SASL.Username, h.SASL.Password)
+	}
+	values = append(values, h.TLS.templatedValues()...)
+	return extractAllVariables(values...)
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h KafkaHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h KafkaHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the SASL password and the TLS client key masked.
+func (h KafkaHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the SASL password and the TLS client key masked.
+func (h KafkaHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h KafkaHostInfo) redacted() HostInfo {
+	if h.SASL != nil {
+		s := *h.SASL
+		s.Password = redactValue(s.Password)
+		h.SASL = &s
+	}
+	h.TLS = h.TLS.redacted()
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h KafkaHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h KafkaHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if len(h.Brokers) == 0 {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "brokers"),
+			Message:  "at least one broker is required",
+		})
+	}
+	for i, broker := range h.Brokers {
+		if host, port, err := net.SplitHostPort(broker); err != nil || host == "" || port == "" {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("hosts", h.Name, "brokers", fmt.Sprint(i)),
+				Message:  fmt.Sprintf("broker %s must be a host and port, such as kafka.example.com:9092", broker),
+			})
+		}
+	}
+
+	if s := h.SASL; s != nil {
+		if !slices.Contains(KafkaSASLMechanisms, s.Mechanism) {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("hosts", h.Name, "sasl", "mechanism"),
+				Message:  fmt.Sprintf("sasl mechanism must be one of %s", strings.Join(KafkaSASLMechanisms, ", ")),
+			})
+		}
+		if s.Username == "" || s.Password == "" {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("hosts", h.Name, "sasl"),
+				Message:  "sasl requires a username and password",
+			})
+		}
+		if s.Mechanism == KafkaSASLPlain && h.TLS == nil {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityWarning,
+				Path:     jsonPointer("hosts", h.Name, "sasl", "mechanism"),
+				Message:  "the PLAIN mechanism sends the password unencrypted unless tls is used",
+			})
+		}
+	}
+
+	diags = append(diags, h.TLS.validate(jsonPointer("hosts", h.Name, "tls"), opts)...)
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a deep copy of the host.
+func (h KafkaHostInfo) Clone() HostInfo {
+	h.Brokers = slices.Clone(h.Brokers)
+	if h.SASL != nil {
+		s := *h.SASL
+		h.SASL = &s
+	}
+	h.TLS = h.TLS.clone()
+	return h
+}
+
+// Equal reports whether the other host is a kafka host with the same settings.
+func (h KafkaHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h KafkaHostInfo) secretFields() []secretField {
+	var fields []secretField
+	if h.SASL != nil {
+		fields = append(fields, secretField{[]string{"sasl", "password"}, h.SASL.Password, "password", "password"})
+	}
+	return append(fields, h.TLS.secretFields()...)
+}
+
+func (h KafkaHostInfo) destinations() destinationList {
+	var l destinationList
+	for _, broker := range h.Brokers {
+		l.addHostPort("brokers", broker, "9092")
+	}
+	return l
+}
+
+func (h KafkaHostInfo) tlsInfo() *TLSInfo {
+	return h.TLS
+}
+
+func (h KafkaHostInfo) hostURL() string {
+	return strings.Join(h.Brokers, ",")
+}
+
+func (h KafkaHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+func (h KafkaHostInfo) hashFields() []hashField {
+	fields := []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"brokers", h.Brokers, legacyExtension},
+		{"topic", h.Topic, legacyExtension},
+		// An empty tls object enables TLS, so its presence is hashed along with its settings.
+		{"tls", h.TLS != nil, legacyExtension},
+	}
+	if h.SASL != nil {
+		fields = append(fields,
+			hashField{"sasl.mechanism", h.SASL.Mechanism, legacyExtension},
+			hashField{"sasl.username", h.SASL.Username, legacyExtension},
+			hashField{"sasl.password", h.SASL.Password, legacyExtension},
+		)
+	}
+	return append(fields, h.TLS.hashFields()...)
+}
@@ -0,0 +1,459 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/tailscale/hujson"
+)
+
+// IDs of the built-in lint rules.  These are stable, and can be used in suppression comments.
+const (
+	LintRulePreferBaseURL         string = "prefer-base-url"
+	LintRuleDuplicateHost         string = "duplicate-host"
+	LintRuleUnusedHost            string = "unused-host"
+	LintRulePlaintextSecret       string = "plaintext-secret"
+	LintRuleDedicatedNonHypermode string = "dedicated-non-hypermode"
+	LintRuleEmptySearchMethods    string = "empty-search-methods"
+)
+
+// Fix is a suggested fix for a diagnostic, as a JSON patch (RFC 6902) to apply to the manifest.
+type Fix struct {
+	Description string
+	Patch       []PatchOperation
+}
+
+// PatchOperation is a single JSON patch operation.
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Lint checks the manifest against the built-in lint rules, and returns a diagnostic for each problem found.
+// Unlike validation, these are not errors, but patterns that are likely to be mistakes or can be written better.
+func Lint(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	diags = append(diags, lintPreferBaseURL(m)...)
+	diags = append(diags, lintDuplicateHosts(m)...)
+	diags = append(diags, lintUnusedHosts(m)...)
+	diags = append(diags, lintPlaintextSecrets(m)...)
+	diags = append(diags, lintDedicatedNonHypermode(m)...)
+	diags = append(diags, lintEmptySearchMethods(m)...)
+	return diags
+}
+
+// LintManifest reads the manifest and checks it against the built-in lint rules and the given custom rules.
+//
+// Diagnostics can be suppressed with a comment of the form "// lint:ignore <rule-id>[,<rule-id>...] [reason]",
+// placed on the line above, or at the end of the line of, the value it applies to.  A suppression also applies to
+// everything nested within the value, and one placed at the top of the file applies to the whole manifest.
+// Fixes are only suggested for manifests in the current format.
+func LintManifest(content []byte, rules ...*LintRuleSet) ([]Diagnostic, error) {
+	ast, err := hujson.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lint manifest: %w", err)
+	}
+
+	m, err := ReadManifest(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lint manifest: %w", err)
+	}
+
+	diags := Lint(&m)
+	for _, rs := range rules {
+		diags = append(diags, rs.Lint(&m)...)
+	}
+
+	suppressed := make(map[string][]string)
+	collectSuppressions(&ast, "", suppressed)
+
+	results := make([]Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		if isSuppressed(d, suppressed) {
+			continue
+		}
+		if !m.IsCurrentVersion() {
+			d.Fix = nil
+		}
+		results = append(results, d)
+	}
+
+	return results, nil
+}
+
+// ApplyFixes applies the fixes of the given diagnostics to the manifest content, in order.
+// Comments are preserved, and the content is not otherwise reformatted.
+func ApplyFixes(content []byte, diags ...Diagnostic) ([]byte, error) {
+	ast, err := hujson.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply fixes: %w", err)
+	}
+
+	for _, d := range diags {
+		if d.Fix == nil {
+			continue
+		}
+		patch, err := json.Marshal(d.Fix.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply fix for %s: %w", d.Rule, err)
+		}
+		if err := ast.Patch(patch); err != nil {
+			return nil, fmt.Errorf("failed to apply fix for %s at %s: %w", d.Rule, d.Path, err)
+		}
+	}
+
+	indentNewMembers(&ast)
+	return ast.Pack(), nil
+}
+
+// indentNewMembers gives object members added by a patch the same whitespace as the members around them,
+// since patching does not format the values it adds.
+func indentNewMembers(v *hujson.Value) {
+	switch val := v.Value.(type) {
+	case *hujson.Object:
+		var nameExtra, valueExtra hujson.Extra
+		for _, member := range val.Members {
+			if len(member.Name.BeforeExtra) > 0 && strings.TrimSpace(string(member.Name.BeforeExtra)) == "" {
+				nameExtra = member.Name.BeforeExtra[strings.LastIndexByte(string(member.Name.BeforeExtra), '\n')+1:]
+				if len(nameExtra) < len(member.Name.BeforeExtra) {
+					nameExtra = append(hujson.Extra("\n"), nameExtra...)
+				}
+			}
+			if len(member.Value.BeforeExtra) > 0 {
+				valueExtra = member.Value.BeforeExtra
+			}
+		}
+
+		// When an object has no other members to copy from, indent one level deeper than its closing brace.
+		if nameExtra == nil {
+			if i := strings.LastIndexByte(string(val.AfterExtra), '\n'); i >= 0 && len(val.Members) > 0 {
+				indent := val.AfterExtra[i:]
+				unit := "  "
+				if strings.Contains(string(indent), "\t") {
+					unit = "\t"
+				}
+				nameExtra = append(append(hujson.Extra(nil), indent...), unit...)
+				valueExtra = hujson.Extra(" ")
+			}
+		}
+
+		for i := range val.Members {
+			member := &val.Members[i]
+			if len(member.Name.BeforeExtra) == 0 && len(member.Value.BeforeExtra) == 0 {
+				member.Name.BeforeExtra = append(hujson.Extra(nil), nameExtra...)
+				member.Value.BeforeExtra = append(hujson.Extra(nil), valueExtra...)
+			}
+			indentNewMembers(&member.Value)
+		}
+	case *hujson.Array:
+		for i := range val.Elements {
+			indentNewMembers(&val.Elements[i])
+		}
+	}
+}
+
+// lintPreferBaseURL reports hosts that use an endpoint for a model, where a base URL and a model path would fit.
+func lintPreferBaseURL(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Hosts) {
+		h, ok := asBuiltin(m.Hosts[name]).httpHost()
+		if !ok || h.Endpoint == "" || h.BaseURL != "" || hasTemplate(h.Endpoint) {
+			continue
+		}
+
+		models := modelsUsingHost(m, name)
+		if len(models) == 0 {
+			continue
+		}
+
+		u, err := url.Parse(h.Endpoint)
+		if err != nil || u.RawQuery != "" || u.Fragment != "" || strings.Trim(u.Path, "/") == "" {
+			continue
+		}
+
+		// The base URL is the endpoint's parent path, so that the host can't reach more than it could before.
+		escaped := u.EscapedPath()
+		i := strings.LastIndex(strings.TrimSuffix(escaped, "/"), "/")
+		path := escaped[i+1:]
+		u.Path, u.RawPath = "", ""
+		baseURL := u.String() + escaped[:i+1]
+
+		patch := []PatchOperation{
+			{Op: "remove", Path: jsonPointer("hosts", name, "endpoint")},
+			{Op: "add", Path: jsonPointer("hosts", name, "baseUrl"), Value: baseURL},
+		}
+		for _, model := range models {
+			patch = append(patch, PatchOperation{Op: "add", Path: jsonPointer("models", model, "path"), Value: path})
+		}
+
+		diags = append(diags, Diagnostic{
+			Severity: SeverityInfo,
+			Path:     jsonPointer("hosts", name, "endpoint"),
+			Rule:     LintRulePreferBaseURL,
+			Message:  fmt.Sprintf("host is used by a model, so use baseUrl %q and set the model path to %q", baseURL, path),
+			Fix: &Fix{
+				Description: "Use the parent path of the endpoint as baseUrl, and move its last segment to the model path",
+				Patch:       patch,
+			},
+		})
+	}
+	return diags
+}
+
+// lintDuplicateHosts reports hosts of the same type that connect to the same URL as an earlier host.
+func lintDuplicateHosts(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	first := make(map[string]string)
+	for _, name := range sortedKeys(m.Hosts) {
+		host := m.Hosts[name]
+		key := asBuiltin(host).hostURL()
+		if key == "" {
+			continue
+		}
+		key = host.HostType() + " " + key
+
+		original, found := first[key]
+		if !found {
+			first[key] = name
+			continue
+		}
+
+		d := Diagnostic{
+			Severity: SeverityWarning,
+			Path:     jsonPointer("hosts", name),
+			Rule:     LintRuleDuplicateHost,
+			Message:  fmt.Sprintf("host has the same URL as host [%s]", original),
+		}
+
+		// Functions also look up hosts by name, so removing the host could break them, and no fix is suggested.
+		if sameHostSettings(m.Hosts[original], host) {
+			d.Message += fmt.Sprintf(", and the same settings, so models and functions could use host [%s] instead", original)
+		}
+
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// lintUnusedHosts reports HTTP and OpenAI hosts that no model uses.
+// Since functions can also use these hosts, these are informational, and no fix is suggested.
+func lintUnusedHosts(m *HypermodeManifest) []Diagnostic {
+	used := make(map[string]bool, len(m.Models))
+	for _, model := range m.Models {
+		used[model.Host] = true
+	}
+
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Hosts) {
+		if t := m.Hosts[name].HostType(); (t != HostTypeHTTP && t != HostTypeOpenAI) || used[name] {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Severity: SeverityInfo,
+			Path:     jsonPointer("hosts", name),
+			Rule:     LintRuleUnusedHost,
+			Message:  "host is not used by any model",
+		})
+	}
+	return diags
+}
+
+// lintPlaintextSecrets reports header, query parameter and metadata values that contain plain-text secrets,
+// as found by the same checks as validation.
+func lintPlaintextSecrets(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Hosts) {
+		for _, f := range asBuiltin(m.Hosts[name]).secretFields() {
+			if len(f.path) != 2 || !slices.Contains([]string{"headers", "queryParameters", "metadata"}, f.path[0]) {
+				continue
+			}
+			if kind, _ := f.detect(); kind == "" {
+				continue
+			}
+
+			variable := suggestVariableName(name, f.variable)
+			path := jsonPointer(append([]string{"hosts", name}, f.path...)...)
+			d := Diagnostic{
+				Severity: SeverityWarning,
+				Path:     path,
+				Rule:     LintRulePlaintextSecret,
+				Message:  fmt.Sprintf("value looks like a secret, so use a template such as {{%s}} instead of a plain-text value", variable),
+			}
+
+			// Values that already use templates are left to be fixed by hand, so that the templates aren't lost.
+			if !hasTemplate(f.value) {
+				d.Fix = &Fix{
+					Description: fmt.Sprintf("Replace the value with the {{%s}} template", variable),
+					Patch:       []PatchOperation{{Op: "replace", Path: path, Value: authSchemePattern.FindString(f.value) + "{{" + variable + "}}"}},
+				}
+			}
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// lintDedicatedNonHypermode reports models that set dedicated, which only applies to models hosted by Hypermode.
+func lintDedicatedNonHypermode(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Models) {
+		model := m.Models[name]
+		if !model.Dedicated || model.Host == "hypermode" {
+			continue
+		}
+		path := jsonPointer("models", name, "dedicated")
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Path:     path,
+			Rule:     LintRuleDedicatedNonHypermode,
+			Message:  "dedicated has no effect, because the model is not hosted by hypermode",
+			Fix: &Fix{
+				Description: "Remove dedicated",
+				Patch:       []PatchOperation{{Op: "remove", Path: path}},
+			},
+		})
+	}
+	return diags
+}
+
+// lintEmptySearchMethods reports collections that have no search methods, and so cannot be searched.
+func lintEmptySearchMethods(m *HypermodeManifest) []Diagnostic {
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Collections) {
+		if len(m.Collections[name].SearchMethods) > 0 {
+			continue
+		}
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Path:     jsonPointer("collections", name),
+			Rule:     LintRuleEmptySearchMethods,
+			Message:  "collection has no search methods",
+		})
+	}
+	return diags
+}
+
+func modelsUsingHost(m *HypermodeManifest, host string) []string {
+	var models []string
+	for _, name := range sortedKeys(m.Models) {
+		if m.Models[name].Host == host {
+			models = append(models, name)
+		}
+	}
+	return models
+}
+
+func sameHostSettings(a, b HostInfo) bool {
+	da, db := toData(a), toData(b)
+	delete(da, "type")
+	delete(db, "type")
+	return reflect.DeepEqual(da, db)
+}
+
+// suggestVariableName returns a variable name made from the given parts, such as MY_API_X_API_KEY.
+func suggestVariableName(parts ...string) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		for _, r := range part {
+			switch {
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				sb.WriteRune(unicode.ToUpper(r))
+			case sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_"):
+				sb.WriteByte('_')
+			}
+		}
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+			sb.WriteByte('_')
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+var suppressionPattern = regexp.MustCompile(`lint:ignore\s+([\w.\-]+(?:\s*,\s*[\w.\-]+)*)`)
+
+// collectSuppressions finds the suppression comments in the value, and records the rule IDs they suppress,
+// keyed by the JSON pointer of the value they apply to.
+func collectSuppressions(v *hujson.Value, path string, suppressed map[string][]string) {
+	if path == "" {
+		addSuppressions(suppressed, "", v.BeforeExtra)
+	}
+
+	switch val := v.Value.(type) {
+	case *hujson.Object:
+		for i := range val.Members {
+			member := &val.Members[i]
+			var name string
+			if lit, ok := member.Name.Value.(hujson.Literal); ok {
+				name = lit.String()
+			}
+			memberPath := path + jsonPointer(name)
+
+			// A comment on the same line as the previous member, or as the opening brace, belongs to that instead.
+			sameLine, leading := splitFirstLine(member.Name.BeforeExtra)
+			if i == 0 {
+				addSuppressions(suppressed, path, sameLine)
+			} else {
+				prevName, _ := val.Members[i-1].Name.Value.(hujson.Literal)
+				addSuppressions(suppressed, path+jsonPointer(prevName.String()), sameLine)
+			}
+			addSuppressions(suppressed, memberPath, leading)
+
+			collectSuppressions(&member.Value, memberPath, suppressed)
+		}
+
+		if n := len(val.Members); n > 0 {
+			lastName, _ := val.Members[n-1].Name.Value.(hujson.Literal)
+			sameLine, _ := splitFirstLine(val.AfterExtra)
+			addSuppressions(suppressed, path+jsonPointer(lastName.String()), sameLine)
+		}
+	case *hujson.Array:
+		for i := range val.Elements {
+			collectSuppressions(&val.Elements[i], path+jsonPointer(fmt.Sprint(i)), suppressed)
+		}
+	}
+}
+
+// splitFirstLine splits the comments and whitespace after the first line break.
+// If there is no line break, it is all on the following line.
+func splitFirstLine(extra hujson.Extra) (first, rest []byte) {
+	if i := strings.IndexByte(string(extra), '\n'); i >= 0 {
+		return extra[:i], extra[i:]
+	}
+	return nil, extra
+}
+
+func addSuppressions(suppressed map[string][]string, path string, comments []byte) {
+	for _, match := range suppressionPattern.FindAllSubmatch(comments, -1) {
+		for _, id := range strings.Split(string(match[1]), ",") {
+			suppressed[path] = append(suppressed[path], strings.TrimSpace(id))
+		}
+	}
+}
+
+func isSuppressed(d Diagnostic, suppressed map[string][]string) bool {
+	if d.Rule == "" {
+		return false
+	}
+	for path, ids := range suppressed {
+		if path != "" && d.Path != path && !strings.HasPrefix(d.Path, path+"/") {
+			continue
+		}
+		for _, id := range ids {
+			if id == d.Rule {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+)
+
+const (
+	LintScopeManifest   string = "manifest"
+	LintScopeModel      string = "model"
+	LintScopeHost       string = "host"
+	LintScopeCollection string = "collection"
+)
+
+// LintRule is a custom lint rule, written as a CEL expression that evaluates to true when the manifest complies with it.
+//
+// The expression can use the "manifest" variable, which contains the parsed manifest, with the same field names as the
+// manifest file, such as host.baseUrl.  Rules with the model, host, or collection scope are evaluated once for each item,
+// with the "name" variable set to the item's name, and the "model", "host", or "collection" variable set to the item itself.
+//
+// Fields that are not set are omitted, including empty strings, zero numbers, false booleans, and empty lists and objects.
+// has() returns false for them, and reading them directly fails to evaluate, which is reported as an error diagnostic.
+// So use has() to check optional fields before reading them, as in "!has(model.path) || model.path.startsWith('v1/')".
+//
+// The scope defaults to manifest.  The severity defaults to warning when the rule is read by ReadLintRules,
+// but rules passed directly to CompileLintRules have the severity they are given, so the zero value is an error.
+type LintRule struct {
+	ID         string   `json:"id"`
+	Scope      string   `json:"scope"`
+	Expression string   `json:"expression"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+}
+
+// LintRuleSet is a set of compiled lint rules.
+type LintRuleSet struct {
+	rules []compiledLintRule
+}
+
+type compiledLintRule struct {
+	LintRule
+	program cel.Program
+}
+
+// ReadLintRules reads and compiles lint rules from a rules file, which has the form {"rules": [...]}.
+// Comments and trailing commas are allowed, as in the manifest.
+func ReadLintRules(content []byte) (*LintRuleSet, error) {
+	data, err := standardizeJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint rules: %w", err)
+	}
+
+	var file struct {
+		Rules []json.RawMessage `json:"rules"`
+	}
+
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to read lint rules: %w", err)
+	}
+
+	rules := make([]LintRule, len(file.Rules))
+	for i, raw := range file.Rules {
+		rules[i].Severity = SeverityWarning
+		if err := json.Unmarshal(raw, &rules[i]); err != nil {
+			return nil, fmt.Errorf("failed to read lint rules: %w", err)
+		}
+	}
+
+	return CompileLintRules(rules...)
+}
+
+// CompileLintRules compiles the lint rules.  An error is returned if any rule is invalid.
+func CompileLintRules(rules ...LintRule) (*LintRuleSet, error) {
+	rs := &LintRuleSet{rules: make([]compiledLintRule, 0, len(rules))}
+	seen := make(map[string]bool, len(rules))
+
+	var errs []error
+	for _, rule := range rules {
+		if rule.ID == "" {
+			errs = append(errs, errors.New("lint rule is missing an id"))
+			continue
+		}
+		if seen[rule.ID] {
+			errs = append(errs, fmt.Errorf("duplicate lint rule id: [%s]", rule.ID))
+			continue
+		}
+		seen[rule.ID] = true
+
+		if rule.Scope == "" {
+			rule.Scope = LintScopeManifest
+		}
+
+		program, err := compileLintExpression(rule.Scope, rule.Expression)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid lint rule [%s]: %w", rule.ID, err))
+			continue
+		}
+
+		rs.rules = append(rs.rules, compiledLintRule{rule, program})
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return rs, nil
+}
+
+func compileLintExpression(scope, expression string) (cel.Program, error) {
+	opts := []cel.EnvOption{
+		ext.Strings(),
+		cel.Variable("manifest", cel.MapType(cel.StringType, cel.DynType)),
+	}
+
+	switch scope {
+	case LintScopeManifest:
+	case LintScopeModel, LintScopeHost, LintScopeCollection:
+		opts = append(opts,
+			cel.Variable("name", cel.StringType),
+			cel.Variable(scope, cel.MapType(cel.StringType, cel.DynType)),
+		)
+	default:
+		return nil, fmt.Errorf("unknown scope: [%s]", scope)
+	}
+
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	if t := ast.OutputType(); !t.IsExactType(cel.BoolType) && !t.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", t)
+	}
+
+	return env.Program(ast)
+}
+
+// Lint evaluates each rule against the manifest, and returns a diagnostic for each rule that the manifest does not
+// comply with.  Rules that fail to evaluate are reported as error diagnostics.
+func (rs *LintRuleSet) Lint(m *HypermodeManifest) []Diagnostic {
+	if rs == nil {
+		return nil
+	}
+
+	data := manifestData(m)
+
+	var diags []Diagnostic
+	for _, rule := range rs.rules {
+		if rule.Scope == LintScopeManifest {
+			diags = append(diags, rule.eval("", map[string]any{"manifest": data})...)
+			continue
+		}
+
+		section, _ := data[rule.Scope+"s"].(map[string]any)
+		for _, name := range sortedKeys(section) {
+			vars := map[string]any{
+				"manifest": data,
+				"name":     name,
+				rule.Scope: section[name],
+			}
+			diags = append(diags, rule.eval(jsonPointer(rule.Scope+"s", name), vars)...)
+		}
+	}
+
+	return diags
+}
+
+func (r compiledLintRule) eval(path string, vars map[string]any) []Diagnostic {
+	out, _, err := r.program.Eval(vars)
+	if err != nil {
+		return []Diagnostic{{
+			Severity: SeverityError,
+			Path:     path,
+			Rule:     r.ID,
+			Message:  fmt.Sprintf("failed to evaluate lint rule: %v (fields that are not set must be checked with has())", err),
+		}}
+	}
+
+	if ok, isBool := out.Value().(bool); !isBool {
+		return []Diagnostic{{
+			Severity: SeverityError,
+			Path:     path,
+			Rule:     r.ID,
+			Message:  fmt.Sprintf("lint rule evaluated to %s, not a bool", out.Type().TypeName()),
+		}}
+	} else if ok {
+		return nil
+	}
+
+	return []Diagnostic{{
+		Severity: r.Severity,
+		Path:     path,
+		Rule:     r.ID,
+		Message:  r.Message,
+	}}
+}
+
+// manifestData returns the manifest as generic data, with the same field names as the manifest file.
+// Names and host types are filled in, and fields that are not set are omitted, as described by pruneData.
+func manifestData(m *HypermodeManifest) map[string]any {
+	models := make(map[string]any, len(m.Models))
+	for name, model := range m.Models {
+		data := toData(model)
+		data["name"] = name
+		models[name] = data
+	}
+
+	hosts := make(map[string]any, len(m.Hosts))
+	for name, host := range m.Hosts {
+		data := hostData(host)
+		data["name"] = name
+		hosts[name] = data
+	}
+
+	collections := make(map[string]any, len(m.Collections))
+	for name, collection := range m.Collections {
+		data := toData(collection)
+		data["name"] = name
+		collections[name] = data
+	}
+
+	return map[string]any{
+		"version":     int64(m.Version),
+		"models":      models,
+		"hosts":       hosts,
+		"collections": collections,
+	}
+}
+
+// hostFileKeys maps the JSON names of host fields to their names in the manifest file, where they differ.
+var hostFileKeys = map[string]string{
+	"baseURL": "baseUrl",
+}
+
+// hostData returns the host as generic data, with the same field names as the manifest file,
+// and with its name and type filled in.
+func hostData(host HostInfo) map[string]any {
+	data := toData(host)
+	for k, fileKey := range hostFileKeys {
+		if v, ok := data[k]; ok {
+			delete(data, k)
+			data[fileKey] = v
+		}
+	}
+	// An empty tls object enables TLS for some host types, so it is kept, unlike other empty values.
+	if _, ok := data["tls"]; !ok && asBuiltin(host).tlsInfo() != nil {
+		data["tls"] = map[string]any{}
+	}
+	data["name"] = host.HostName()
+	data["type"] = host.HostType()
+	return data
+}
+
+// toData returns the value as generic data, using the JSON names of its fields, with unset fields pruned.
+func toData(v any) map[string]any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		// Manifest items are plain data, so this should never happen.
+		panic(fmt.Sprintf("failed to encode manifest data: %v", err))
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(b, &data); err != nil {
+		panic(fmt.Sprintf("failed to decode manifest data: %v", err))
+	}
+
+	pruneData(data)
+	return data
+}
+
+// pruneData removes nulls, empty strings, zero numbers, false booleans, and empty maps and slices,
+// including those in nested maps and in maps within slices, so that unset fields can be detected with has().
+func pruneData(data map[string]any) {
+	for k, v := range data {
+		switch val := v.(type) {
+		case nil:
+			delete(data, k)
+		case string:
+			if val == "" {
+				delete(data, k)
+			}
+		case float64:
+			if val == 0 {
+				delete(data, k)
+			}
+		case bool:
+			if !val {
+				delete(data, k)
+			}
+		case []any:
+			for _, item := range val {
+				if m, ok := item.(map[string]any); ok {
+					pruneData(m)
+				}
+			}
+			if len(val) == 0 {
+				delete(data, k)
+			}
+		case map[string]any:
+			pruneData(val)
+			if len(val) == 0 {
+				delete(data, k)
+			}
+		}
+	}
+}
@@ -5,9 +5,12 @@
 package manifest
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"sort"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"github.com/tailscale/hujson"
@@ -27,7 +30,115 @@ type HostInfo interface {
 	HostName() string
 	HostType() string
 	GetVariables() []string
+	// Hash returns the hash of the host settings, using the version described on HashVersionDefault.
 	Hash() string
+	HashWith(version HashVersion) string
+	// String and LogValue return the host settings with any secrets masked.
+	String() string
+	LogValue() slog.Value
+	// Validate checks the host settings, with the default validation options.
+	Validate() []Diagnostic
+	// Clone returns a deep copy of the host, which shares no maps, slices, or pointers with the original.
+	Clone() HostInfo
+	// Equal reports whether the other host has the same type and settings.
+	Equal(other HostInfo) bool
+}
+
+// builtinHost is implemented by every host type in this package, so that validation, redaction, secret detection,
+// policy checks, and lint rules can't silently skip one of them.  Host types from outside this package are
+// handled by externalHost instead.
+type builtinHost interface {
+	HostInfo
+	validate(opts ValidationOptions) []Diagnostic
+	redacted() HostInfo
+	secretFields() []secretField
+	destinations() destinationList
+	tlsInfo() *TLSInfo
+	hostURL() string
+	// httpHost returns the http settings that the host's requests are made with, if it is an http based host.
+	httpHost() (HTTPHostInfo, bool)
+}
+
+var (
+	_ builtinHost = HTTPHostInfo{}
+	_ builtinHost = GraphQLHostInfo{}
+	_ builtinHost = OpenAIHostInfo{}
+	_ builtinHost = PostgresqlHostInfo{}
+	_ builtinHost = MySQLHostInfo{}
+	_ builtinHost = DgraphHostInfo{}
+	_ builtinHost = Neo4jHostInfo{}
+	_ builtinHost = MongoDBHostInfo{}
+	_ builtinHost = RedisHostInfo{}
+	_ builtinHost = GRPCHostInfo{}
+	_ builtinHost = S3HostInfo{}
+	_ builtinHost = KafkaHostInfo{}
+	_ builtinHost = SQLiteHostInfo{}
+)
+
+func asBuiltin(host HostInfo) builtinHost {
+	if h, ok := host.(builtinHost); ok {
+		return h
+	}
+	return externalHost{host}
+}
+
+// externalHost wraps a host type from outside this package, whose settings aren't known here.  It is validated
+// with its own Validate method, its settings are hidden when it is formatted, and it can't pass a policy check.
+type externalHost struct {
+	HostInfo
+}
+
+func (h externalHost) validate(ValidationOptions) []Diagnostic {
+	return h.Validate()
+}
+
+func (h externalHost) redacted() HostInfo {
+	return opaqueHost(h)
+}
+
+func (h externalHost) secretFields() []secretField {
+	return nil
+}
+
+func (h externalHost) destinations() destinationList {
+	var l destinationList
+	l.fail("type", fmt.Errorf("the destination of host type %s can't be checked against the policy", h.HostType()))
+	return l
+}
+
+func (h externalHost) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h externalHost) hostURL() string {
+	return ""
+}
+
+func (h externalHost) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+// opaqueHost formats as only the name and type of a host, since the secrets in its other settings can't be masked.
+type opaqueHost externalHost
+
+func (opaqueHost) MarshalJSON() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+// GetHost returns the named host as type T, such as HTTPHostInfo.  The error wraps ErrHostNotFound if there is no
+// such host, or ErrHostTypeMismatch if the host is not of type T.
+func GetHost[T HostInfo](m *HypermodeManifest, name string) (T, error) {
+	var zero T
+	host, ok := m.Hosts[name]
+	if !ok {
+		return zero, fmt.Errorf("%w: [%s]", ErrHostNotFound, name)
+	}
+
+	h, ok := host.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: host [%s] is a %s host, not %T", ErrHostTypeMismatch, name, host.HostType(), zero)
+	}
+	return h, nil
 }
 
 type HypermodeManifest struct {
@@ -54,11 +165,100 @@ func (m *HypermodeManifest) GetHostVariables() map[string][]string {
 	return results
 }
 
+// GetHostSecretVariables returns the variables of each host that hold secrets, such as passwords,
+// API keys and tokens, so that they can be stored and displayed accordingly.  Hosts that don't use
+// any secret variables are omitted.
+func (m *HypermodeManifest) GetHostSecretVariables() map[string][]string {
+	results := make(map[string][]string, len(m.Hosts))
+
+	for _, host := range m.Hosts {
+		vars := secretVariables(host)
+		if len(vars) > 0 {
+			results[host.HostName()] = vars
+		}
+	}
+
+	return results
+}
+
+// Hash returns a digest of the whole manifest, covering every model, host and collection.
+// It uses HashVersionDefault.
+func (m *HypermodeManifest) Hash() string {
+	return m.HashWith(HashVersionDefault)
+}
+
+// HashWith returns a digest of the whole manifest, computing each model, host and collection
+// hash with the given version.
+func (m *HypermodeManifest) HashWith(version HashVersion) string {
+	models := make(map[string]string, len(m.Models))
+	for name, model := range m.Models {
+		models[name] = model.HashWith(version)
+	}
+
+	hosts := make(map[string]string, len(m.Hosts))
+	for name, host := range m.Hosts {
+		hosts[name] = host.HashWith(version)
+	}
+
+	collections := make(map[string]string, len(m.Collections))
+	for name, collection := range m.Collections {
+		collections[name] = collection.HashWith(version)
+	}
+
+	return computeHash(version, []hashField{
+		{"version", m.Version, legacyExtension},
+		{"models", models, legacyExtension},
+		{"hosts", hosts, legacyExtension},
+		{"collections", collections, legacyExtension},
+	})
+}
+
+// String returns the manifest as JSON, with any secrets in the hosts masked.
+func (m HypermodeManifest) String() string {
+	return formatData(manifestData(m.redacted()))
+}
+
+// LogValue returns the manifest for logging, with any secrets in the hosts masked.
+func (m HypermodeManifest) LogValue() slog.Value {
+	return dataLogValue(manifestData(m.redacted()))
+}
+
+func (m HypermodeManifest) redacted() *HypermodeManifest {
+	hosts := make(map[string]HostInfo, len(m.Hosts))
+	for name, host := range m.Hosts {
+		hosts[name] = asBuiltin(host).redacted()
+	}
+	m.Hosts = hosts
+	return &m
+}
+
 func IsCurrentVersion(version int) bool {
 	return version == currentVersion
 }
 
+// ValidateManifest validates the manifest against the JSON schema, and checks it for other problems
+// using the default validation options.  Only error diagnostics cause validation to fail.
+// Use ValidateManifestWithOptions to get all diagnostics, or to change the options.
 func ValidateManifest(content []byte) error {
+	diags, err := ValidateManifestWithOptions(content, ValidationOptions{})
+	if err != nil {
+		return err
+	}
+
+	var errs []Diagnostic
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Diagnostics: errs}
+	}
+
+	return nil
+}
+
+func validateSchema(content []byte) error {
 	sch, err := jsonschema.CompileString("hypermode.json", schemaContent)
 	if err != nil {
 		return err
@@ -151,6 +351,76 @@ func parseManifestJson(data []byte, manifest *HypermodeManifest) error {
 			}
 			h.Name = name
 			manifest.Hosts[name] = h
+		case HostTypeMySQL:
+			var h MySQLHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeNeo4j:
+			var h Neo4jHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeMongoDB:
+			var h MongoDBHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeRedis:
+			var h RedisHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeGRPC:
+			var h GRPCHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeGraphQL:
+			var h GraphQLHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeS3:
+			var h S3HostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeKafka:
+			var h KafkaHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeSQLite, HostTypeLocalFile:
+			var h SQLiteHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
+		case HostTypeOpenAI:
+			var h OpenAIHostInfo
+			if err := json.Unmarshal(rawHost, &h); err != nil {
+				return fmt.Errorf("failed to parse manifest: %w", err)
+			}
+			h.Name = name
+			manifest.Hosts[name] = h
 		default:
 			return fmt.Errorf("unknown host type: [%s]", hostType.String())
 		}
@@ -161,9 +431,19 @@ func parseManifestJson(data []byte, manifest *HypermodeManifest) error {
 	return nil
 }
 
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // standardizeJSON removes comments and trailing commas to make the JSON valid
 func standardizeJSON(b []byte) ([]byte, error) {
-	ast, err := hujson.Parse(b)
+	// Standardize works in place, so parse a copy to leave the caller's content intact.
+	ast, err := hujson.Parse(bytes.Clone(b))
 	if err != nil {
 		return b, err
 	}
@@ -4,12 +4,6 @@
 
 package manifest
 
-import (
-	"crypto/sha256"
-	"encoding/hex"
-	"fmt"
-)
-
 type ModelInfo struct {
 	Name        string `json:"-"`
 	SourceModel string `json:"sourceModel"`
@@ -20,19 +14,23 @@ type ModelInfo struct {
 }
 
 func (m ModelInfo) Hash() string {
-	// Concatenate the attributes into a single string
-	data := fmt.Sprintf("%v|%v|%v|%v", m.Name, m.SourceModel, m.Provider, m.Host)
-	// Don't include the "dedicated" attribute if host is NOT "hypermode" or
-	// if it's NOT "dedicated" (default)
-	if m.Host == "hypermode" && m.Dedicated {
-		data += fmt.Sprintf("|%v", m.Dedicated)
-	}
-
-	// Compute the SHA-256 hash
-	hash := sha256.Sum256([]byte(data))
+	return m.HashWith(HashVersionLegacy)
+}
 
-	// Convert the hash to a hexadecimal string
-	hashStr := hex.EncodeToString(hash[:])
+func (m ModelInfo) HashWith(version HashVersion) string {
+	return computeHash(version, m.hashFields())
+}
 
-	return hashStr
+func (m ModelInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", m.Name, legacyPositional},
+		{"sourceModel", m.SourceModel, legacyPositional},
+		{"provider", m.Provider, legacyPositional},
+		{"host", m.Host, legacyPositional},
+		// Don't include the "dedicated" attribute if host is NOT "hypermode" or
+		// if it's NOT "dedicated" (default)
+		{"dedicated", m.Host == "hypermode" && m.Dedicated, legacyPositionalIfSet},
+		// NOTE: The Path field was not included in the legacy model hash.
+		{"path", m.Path, legacyOmitted},
+	}
 }
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	HostTypeNeo4j string = "neo4j"
+)
+
+// Neo4jURISchemes are the URI schemes supported by Neo4j drivers.  The "+s" variants use TLS with
+// certificate verification, and the "+ssc" variants use TLS and accept self-signed certificates.
+var Neo4jURISchemes = []string{"neo4j", "neo4j+s", "neo4j+ssc", "bolt", "bolt+s", "bolt+ssc"}
+
+type Neo4jHostInfo struct {
+	Name     string `json:"-"`
+	Type     string `json:"type"`
+	URI      string `json:"uri"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Database string `json:"database,omitempty"`
+}
+
+func (h Neo4jHostInfo) HostName() string {
+	return h.Name
+}
+
+func (Neo4jHostInfo) HostType() string {
+	return HostTypeNeo4j
+}
+
+func (h Neo4jHostInfo) GetVariables() []string {
+	return extractAllVariables(h.URI, h.Username, h.Password)
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h Neo4jHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h Neo4jHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the password masked.
+func (h Neo4jHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the password masked.
+func (h Neo4jHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h Neo4jHostInfo) redacted() HostInfo {
+	h.URI = redactURL(h.URI, false)
+	h.Password = redactValue(h.Password)
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h Neo4jHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h Neo4jHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	scheme, _, _ := strings.Cut(h.URI, "://")
+	switch {
+	case h.URI == "":
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "uri"),
+			Message:  "uri is required",
+		})
+	case !hasTemplate(scheme) && !slices.Contains(Neo4jURISchemes, scheme):
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "uri"),
+			Message:  fmt.Sprintf("uri scheme must be one of %s", strings.Join(Neo4jURISchemes, ", ")),
+		})
+	}
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a copy of the host.
+func (h Neo4jHostInfo) Clone() HostInfo {
+	return h
+}
+
+// Equal reports whether the other host is a neo4j host with the same settings.
+func (h Neo4jHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h Neo4jHostInfo) secretFields() []secretField {
+	fields := urlSecretFields([]string{"uri"}, h.URI)
+	return append(fields, secretField{[]string{"password"}, h.Password, "password", "password"})
+}
+
+func (h Neo4jHostInfo) destinations() destinationList {
+	var l destinationList
+	l.addServiceURL("uri", h.URI, "7687")
+	return l
+}
+
+func (h Neo4jHostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h Neo4jHostInfo) hostURL() string {
+	return h.URI
+}
+
+func (h Neo4jHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+func (h Neo4jHostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"uri", h.URI, legacyExtension},
+		{"username", h.Username, legacyExtension},
+		{"database", h.Database, legacyExtension},
+		{"password", h.Password, legacyExtension},
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// oauth2ExpiryDelta is how long before its expiry a cached token is refreshed.  For tokens with a short
+	// lifetime, the delta is limited to a quarter of the lifetime, so that they are still cached.
+	oauth2ExpiryDelta = 30 * time.Second
+
+	// oauth2FetchTimeout limits how long a token request can take.  The request is shared by all callers
+	// waiting for a token, so it doesn't use the context of any one of them.
+	oauth2FetchTimeout = 30 * time.Second
+)
+
+// oauth2Transport authenticates requests with a bearer token obtained using the OAuth2 client credentials flow.
+// Tokens are cached until shortly before they expire.
+type oauth2Transport struct {
+	auth *AuthInfo
+	base http.RoundTripper
+	now  func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time
+	fetch   *oauth2Fetch
+}
+
+// oauth2Fetch is a token request in progress.  done is closed when it completes.
+type oauth2Fetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newOAuth2Transport(auth *AuthInfo, base http.RoundTripper) *oauth2Transport {
+	return &oauth2Transport{
+		auth: auth,
+		base: base,
+		now:  time.Now,
+	}
+}
+
+func (t *oauth2Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.getToken(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the original request.
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := t.base.RoundTrip(r)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		// The token may have been revoked.  Fetch a new one for the next request.
+		t.invalidate(token)
+	}
+	return resp, err
+}
+
+// getToken returns the cached token, or waits for a new one.  Concurrent callers share a single token request,
+// and a caller whose context is cancelled stops waiting without affecting the others.
+func (t *oauth2Transport) getToken(req *http.Request) (string, error) {
+	t.mu.Lock()
+	if t.token != "" && (t.refresh.IsZero() || t.now().Before(t.refresh)) {
+		token := t.token
+		t.mu.Unlock()
+		return token, nil
+	}
+
+	f := t.fetch
+	if f == nil {
+		f = &oauth2Fetch{done: make(chan struct{})}
+		t.fetch = f
+		go t.runFetch(req, f)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-req.Context().Done():
+		return "", req.Context().Err()
+	}
+}
+
+func (t *oauth2Transport) runFetch(req *http.Request, f *oauth2Fetch) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), oauth2FetchTimeout)
+	defer cancel()
+
+	token, expiresIn, err := t.fetchToken(ctx)
+
+	t.mu.Lock()
+	if err == nil {
+		t.token = token
+		if expiresIn > 0 {
+			t.refresh = t.now().Add(expiresIn - min(oauth2ExpiryDelta, expiresIn/4))
+		} else {
+			t.refresh = time.Time{}
+		}
+	}
+	t.fetch = nil
+	t.mu.Unlock()
+
+	f.token, f.err = token, err
+	close(f.done)
+}
+
+func (t *oauth2Transport) invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+		t.refresh = time.Time{}
+	}
+}
+
+func (t *oauth2Transport) fetchToken(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if t.auth.ClientAuthMethod == OAuth2ClientSecretPost {
+		form.Set("client_id", t.auth.ClientID)
+		form.Set("client_secret", t.auth.ClientSecret)
+	}
+	if len(t.auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.auth.Scopes, " "))
+	}
+	if t.auth.Audience != "" {
+		form.Set("audience", t.auth.Audience)
+	}
+
+	tokenReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.auth.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create oauth2 token request: %w", err)
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if t.auth.ClientAuthMethod != OAuth2ClientSecretPost {
+		// RFC 6749 section 2.3.1 requires the credentials to be form encoded before they are used for Basic authentication.
+		tokenReq.SetBasicAuth(url.QueryEscape(t.auth.ClientID), url.QueryEscape(t.auth.ClientSecret))
+	}
+	tokenReq.Header.Set("Accept", "application/json")
+
+	resp, err := t.base.RoundTrip(tokenReq)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request oauth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read oauth2 token response: %w", err)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+
+	// The response body is not included in errors, since it may contain credentials.  Only the standard
+	// error code is reported, if the body has one.
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(body, &result) == nil && oauth2ErrorCodePattern.MatchString(result.Error) {
+			return "", 0, fmt.Errorf("oauth2 token request failed with status %d: %s", resp.StatusCode, result.Error)
+		}
+		return "", 0, fmt.Errorf("oauth2 token request failed with status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", 0, fmt.Errorf("failed to parse oauth2 token response: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", 0, errors.New("oauth2 token response did not include an access token")
+	}
+	if result.TokenType != "" && !strings.EqualFold(result.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("unsupported oauth2 token type: [%s]", result.TokenType)
+	}
+
+	return result.AccessToken, time.Duration(result.ExpiresIn) * time.Second, nil
+}
+
+// oauth2ErrorCodePattern matches the error codes defined by RFC 6749, such as "invalid_client".
+var oauth2ErrorCodePattern = regexp.MustCompile(`^[a-z_]{1,64}$`)
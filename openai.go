@@ -0,0 +1,186 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const (
+	HostTypeOpenAI string = "openai"
+)
+
+// OpenAIHostInfo is a host for a service that implements the OpenAI API, such as OpenAI itself,
+// Azure OpenAI, vLLM, Ollama or LM Studio.  Models that use the host must set a sourceModel,
+// which is sent as the model name.
+type OpenAIHostInfo struct {
+	Name string `json:"-"`
+	Type string `json:"type"`
+
+	// BaseURL is the URL that API paths are relative to, such as "https://api.openai.com/v1/".
+	// It must end with a trailing slash.
+	BaseURL string `json:"baseURL"`
+
+	// APIKey is a template referencing the variable that contains the API key.  It can be omitted
+	// for local services that don't need a key.
+	APIKey string `json:"apiKey,omitempty"`
+
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
+
+	// APIVersion is the Azure OpenAI api-version, such as "2024-06-01".  When it is set, the key is
+	// sent in the api-key header, as expected by Azure, instead of as a bearer token.
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+func (h OpenAIHostInfo) HostName() string {
+	return h.Name
+}
+
+func (OpenAIHostInfo) HostType() string {
+	return HostTypeOpenAI
+}
+
+func (h OpenAIHostInfo) GetVariables() []string {
+	return extractAllVariables(h.APIKey, h.BaseURL, h.Organization, h.Project)
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h OpenAIHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h OpenAIHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the API key masked.
+func (h OpenAIHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the API key masked.
+func (h OpenAIHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h OpenAIHostInfo) redacted() HostInfo {
+	h.BaseURL = redactURL(h.BaseURL, true)
+	h.APIKey = redactValue(h.APIKey)
+	return h
+}
+
+// IsAzure reports whether the host is an Azure OpenAI service.
+func (h OpenAIHostInfo) IsAzure() bool {
+	return h.APIVersion != ""
+}
+
+// HTTPHost returns an http host with the same base URL, and the headers and query parameters
+// that the OpenAI API expects for the host's settings.
+func (h OpenAIHostInfo) HTTPHost() HTTPHostInfo {
+	headers := make(map[string]string)
+	var query map[string]string
+	if h.IsAzure() {
+		if h.APIKey != "" {
+			headers["api-key"] = h.APIKey
+		}
+		query = map[string]string{"api-version": h.APIVersion}
+	} else if h.APIKey != "" {
+		headers["Authorization"] = "Bearer " + h.APIKey
+	}
+	if h.Organization != "" {
+		headers["OpenAI-Organization"] = h.Organization
+	}
+	if h.Project != "" {
+		headers["OpenAI-Project"] = h.Project
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+
+	return HTTPHostInfo{
+		Name:            h.Name,
+		Type:            HostTypeHTTP,
+		BaseURL:         h.BaseURL,
+		Headers:         headers,
+		QueryParameters: query,
+	}
+}
+
+// Validate checks the host settings with the default validation options.
+func (h OpenAIHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h OpenAIHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	switch {
+	case h.BaseURL == "":
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "baseUrl"),
+			Message:  "baseUrl is required",
+		})
+	case !strings.HasSuffix(h.BaseURL, "/"):
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "baseUrl"),
+			Message:  ErrMissingTrailingSlash.Error(),
+		})
+	}
+
+	if h.IsAzure() && h.APIKey == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "apiKey"),
+			Message:  "apiKey is required for Azure OpenAI",
+		})
+	}
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a copy of the host.
+func (h OpenAIHostInfo) Clone() HostInfo {
+	return h
+}
+
+// Equal reports whether the other host is an openai host with the same settings.
+func (h OpenAIHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h OpenAIHostInfo) secretFields() []secretField {
+	fields := urlSecretFields([]string{"baseUrl"}, h.BaseURL)
+	return append(fields, secretField{[]string{"apiKey"}, h.APIKey, "API key", "API key"})
+}
+
+func (h OpenAIHostInfo) destinations() destinationList {
+	return h.HTTPHost().destinations()
+}
+
+func (h OpenAIHostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h OpenAIHostInfo) hostURL() string {
+	return h.BaseURL
+}
+
+func (h OpenAIHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return h.HTTPHost(), true
+}
+
+func (h OpenAIHostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"baseURL", h.BaseURL, legacyExtension},
+		{"apiKey", h.APIKey, legacyExtension},
+		{"organization", h.Organization, legacyExtension},
+		{"project", h.Project, legacyExtension},
+		{"apiVersion", h.APIVersion, legacyExtension},
+	}
+}
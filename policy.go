@@ -0,0 +1,330 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Policy restricts the network destinations that a manifest's hosts may connect to, such as
+// for a production environment.  Empty allow lists allow everything, and deny lists take
+// precedence over allow lists.  Host names are checked as written, without resolving them.
+type Policy struct {
+	// AllowedDomains lists the host names that may be used.  An entry such as "*.example.com"
+	// matches any subdomain of example.com.  Otherwise, the host name must match exactly.
+	AllowedDomains []string
+	DeniedDomains  []string
+
+	// AllowedCIDRs lists the IP ranges that may be used by hosts given as IP addresses.
+	// When either AllowedDomains or AllowedCIDRs is set, every host must match one of them.
+	AllowedCIDRs []string
+	DeniedCIDRs  []string
+
+	// DenyPrivateNetworks denies localhost, and loopback, private, link-local and unspecified IP addresses.
+	DenyPrivateNetworks bool
+
+	// AllowedSchemes lists the URL schemes that http hosts may use, such as "https".
+	AllowedSchemes []string
+
+	// AllowedPorts lists the ports that may be used.  Default ports are used when a port is not given.
+	AllowedPorts []int
+
+	// AllowedHostTypes lists the host types that may be used, such as "http" or "postgresql".
+	AllowedHostTypes []string
+
+	// AllowInsecureSkipVerify allows TLS certificate verification to be disabled.
+	AllowInsecureSkipVerify bool
+}
+
+// Rules of the diagnostics for policy violations.
+const (
+	PolicyRule string = "policy"
+
+	// PolicyRuleInsecureSkipVerify is also the rule of the diagnostic that validation reports for disabled
+	// certificate verification, so that the problem is reported once when a policy is checked during validation.
+	PolicyRuleInsecureSkipVerify string = "insecure-skip-verify"
+)
+
+// Violation is a single way in which a manifest does not comply with a policy.
+type Violation struct {
+	Host string
+	// Path is a JSON pointer to the value that violates the policy, such as "/hosts/my-api/baseUrl".
+	Path    string
+	Message string
+	// Rule is PolicyRule, or a more specific rule such as PolicyRuleInsecureSkipVerify.
+	Rule string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Diagnostic returns the violation as an error diagnostic.
+func (v Violation) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Path:     v.Path,
+		Message:  v.Message,
+		Rule:     v.Rule,
+	}
+}
+
+// CheckPolicy checks the manifest's hosts against the policy, and returns any violations.
+// Values that contain templates are checked after the templates are removed.  If a template
+// is used where the destination host is expected, the destination can't be checked, and a
+// violation is returned.
+func CheckPolicy(manifest HypermodeManifest, policy Policy) []Violation {
+	var violations []Violation
+	for _, name := range sortedKeys(manifest.Hosts) {
+		violations = append(violations, policy.checkHost(manifest.Hosts[name])...)
+	}
+	return violations
+}
+
+// destination is a network address that a host connects to.
+type destination struct {
+	field  string
+	scheme string
+	host   string
+	port   string
+}
+
+func (p Policy) checkHost(host HostInfo) []Violation {
+	name := host.HostName()
+	violation := func(field, format string, args ...any) Violation {
+		return Violation{
+			Host:    name,
+			Path:    jsonPointer(append([]string{"hosts", name}, strings.Split(field, "/")...)...),
+			Message: fmt.Sprintf(format, args...),
+			Rule:    PolicyRule,
+		}
+	}
+
+	var violations []Violation
+	if len(p.AllowedHostTypes) > 0 && !slices.Contains(p.AllowedHostTypes, host.HostType()) {
+		violations = append(violations, violation("type", "host type %s is not allowed by the policy", host.HostType()))
+	}
+
+	l := asBuiltin(host).destinations()
+	for _, field := range sortedKeys(l.errs) {
+		violations = append(violations, violation(field, "%v", l.errs[field]))
+	}
+
+	for _, d := range l.dests {
+		if d.scheme != "" && len(p.AllowedSchemes) > 0 && !slices.Contains(p.AllowedSchemes, d.scheme) {
+			violations = append(violations, violation(d.field, "scheme %s is not allowed by the policy", d.scheme))
+		}
+		if msg := p.checkAddress(d.host); msg != "" {
+			violations = append(violations, violation(d.field, "%s", msg))
+		}
+		if len(p.AllowedPorts) > 0 && d.port != "" {
+			port, err := strconv.Atoi(d.port)
+			if err != nil || !slices.Contains(p.AllowedPorts, port) {
+				violations = append(violations, violation(d.field, "port %s is not allowed by the policy", d.port))
+			}
+		}
+	}
+
+	if t := asBuiltin(host).tlsInfo(); t != nil && t.InsecureSkipVerify && !p.AllowInsecureSkipVerify {
+		v := violation("tls/insecureSkipVerify", "insecureSkipVerify is not allowed by the policy")
+		v.Rule = PolicyRuleInsecureSkipVerify
+		violations = append(violations, v)
+	}
+
+	return violations
+}
+
+// checkAddress returns a message describing why the host name or IP address is not allowed, or an empty string.
+func (p Policy) checkAddress(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+
+	if p.DenyPrivateNetworks {
+		if ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()) {
+			return fmt.Sprintf("private network address %s is not allowed by the policy", host)
+		}
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Sprintf("%s is not allowed by the policy", host)
+		}
+	}
+
+	if ip != nil {
+		if cidrsContain(p.DeniedCIDRs, ip) {
+			return fmt.Sprintf("address %s is denied by the policy", host)
+		}
+	} else if domainsMatch(p.DeniedDomains, host) {
+		return fmt.Sprintf("domain %s is denied by the policy", host)
+	}
+
+	if len(p.AllowedDomains) == 0 && len(p.AllowedCIDRs) == 0 {
+		return ""
+	}
+	if ip != nil && cidrsContain(p.AllowedCIDRs, ip) {
+		return ""
+	}
+	if ip == nil && domainsMatch(p.AllowedDomains, host) {
+		return ""
+	}
+	return fmt.Sprintf("%s is not in the allowed domains or address ranges of the policy", host)
+}
+
+func domainsMatch(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func cidrsContain(cidrs []string, ip net.IP) bool {
+	for _, c := range cidrs {
+		if _, n, err := net.ParseCIDR(c); err == nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// destinationList is the network destinations of a host, along with errors for fields whose destinations
+// can't be determined, keyed by field.
+type destinationList struct {
+	dests []destination
+	errs  map[string]error
+}
+
+func (l *destinationList) add(d destination) {
+	l.dests = append(l.dests, d)
+}
+
+func (l *destinationList) fail(field string, err error) {
+	if l.errs == nil {
+		l.errs = make(map[string]error)
+	}
+	l.errs[field] = err
+}
+
+// addURL adds the destination of an http URL, if it is set.
+func (l *destinationList) addURL(field, value string) {
+	if value == "" {
+		return
+	}
+	d, err := urlDestination(field, value)
+	if err != nil {
+		l.fail(field, err)
+		return
+	}
+	l.add(d)
+}
+
+// addServiceURL adds the destination of a URL or connection string that is not used for http requests, if it is set.
+// Allowed schemes only apply to http hosts, so the scheme is not kept.
+func (l *destinationList) addServiceURL(field, value, defaultPort string) {
+	if value == "" {
+		return
+	}
+	d, err := urlDestination(field, value)
+	if err != nil {
+		l.fail(field, err)
+		return
+	}
+	d.scheme = ""
+	if d.port == "" {
+		d.port = defaultPort
+	}
+	l.add(d)
+}
+
+// addHostPort adds the destination of an address such as "host:port".
+func (l *destinationList) addHostPort(field, hostport, defaultPort string) {
+	if err := templatedHost(hostport); err != nil {
+		l.fail(field, err)
+		return
+	}
+	d := destination{field: field}
+	d.host, d.port = splitHostPort(hostport, defaultPort)
+	l.add(d)
+}
+
+// addGRPCTarget adds the destination of a gRPC target, such as "host:port" or "dns:///host:port".
+// The resolver scheme and authority are not part of the destination.
+func (l *destinationList) addGRPCTarget(field, target string) {
+	if strings.HasPrefix(target, "unix:") || strings.HasPrefix(target, "unix-abstract:") {
+		// A unix socket, which has no network destination.
+		return
+	}
+	if _, rest, ok := strings.Cut(target, "://"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 {
+			target = rest[i+1:]
+		}
+	}
+	l.addHostPort(field, target, "")
+}
+
+func urlDestination(field, value string) (destination, error) {
+	if err := templatedHost(hostPart(value)); err != nil {
+		return destination{}, err
+	}
+
+	u, err := url.Parse(templateRegex.ReplaceAllString(value, "template"))
+	if err != nil {
+		return destination{}, fmt.Errorf("failed to parse url: %v", err)
+	}
+
+	d := destination{
+		field:  field,
+		scheme: u.Scheme,
+	}
+
+	// url.Parse doesn't split multiple hosts, so the port is only separated here for single hosts.
+	if strings.Contains(u.Host, ",") {
+		d.host = u.Host
+	} else {
+		d.host, d.port = u.Hostname(), u.Port()
+		if d.port == "" {
+			d.port = defaultPort(u.Scheme)
+		}
+	}
+	return d, nil
+}
+
+// hostPart returns the host and port portion of a URL, without parsing it.
+func hostPart(value string) string {
+	if _, rest, ok := strings.Cut(value, "://"); ok {
+		value = rest
+	}
+	if i := strings.IndexAny(value, "/?#"); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.LastIndex(value, "@"); i >= 0 {
+		value = value[i+1:]
+	}
+	return value
+}
+
+func templatedHost(host string) error {
+	if hasTemplate(host) {
+		return fmt.Errorf("the destination can't be checked against the policy, because it contains a template: %s", host)
+	}
+	return nil
+}
+
+func splitHostPort(hostport, defaultPort string) (string, string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return strings.Trim(hostport, "[]"), defaultPort
+	}
+	return host, port
+}
@@ -5,9 +5,10 @@
 package manifest
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
 )
 
 const (
@@ -33,14 +34,140 @@ func (h PostgresqlHostInfo) GetVariables() []string {
 }
 
 func (h PostgresqlHostInfo) Hash() string {
-	// Concatenate the attributes into a single string
-	data := fmt.Sprintf("%v|%v|%v", h.Name, h.Type, h.ConnStr)
+	return h.HashWith(HashVersionLegacy)
+}
+
+func (h PostgresqlHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the password in the connection string masked.
+func (h PostgresqlHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the password in the connection string masked.
+func (h PostgresqlHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h PostgresqlHostInfo) redacted() HostInfo {
+	h.ConnStr = redactURL(h.ConnStr, false)
+	return h
+}
+
+// Validate checks the host settings with the default validation options.
+func (h PostgresqlHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h PostgresqlHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if h.ConnStr == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "connString"),
+			Message:  "connString is required",
+		})
+	}
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a copy of the host.
+func (h PostgresqlHostInfo) Clone() HostInfo {
+	return h
+}
 
-	// Compute the SHA-256 hash
-	hash := sha256.Sum256([]byte(data))
+// Equal reports whether the other host is a postgresql host with the same settings.
+func (h PostgresqlHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
 
-	// Convert the hash to a hexadecimal string
-	hashStr := hex.EncodeToString(hash[:])
+func (h PostgresqlHostInfo) secretFields() []secretField {
+	return urlSecretFields([]string{"connString"}, h.ConnStr)
+}
+
+// destinations returns the hosts of the connection string.  Hosts can be given in the URI, and in the host and hostaddr
+// query parameters, each of which can list multiple hosts, such as "host1:5432,host2:5432".  An empty host connects
+// to a unix socket or to localhost, so it is reported as localhost, as are socket paths.
+func (h PostgresqlHostInfo) destinations() destinationList {
+	var l destinationList
+	d, err := urlDestination("connString", h.ConnStr)
+	if err != nil {
+		l.fail("connString", err)
+		return l
+	}
+
+	_, query, _ := strings.Cut(h.ConnStr, "?")
+	query, _, _ = strings.Cut(query, "#")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		l.fail("connString", fmt.Errorf("failed to parse connection string parameters: %v", err))
+		return l
+	}
+
+	add := func(host, port string) {
+		host = strings.TrimSpace(host)
+		if host == "" || strings.HasPrefix(host, "/") {
+			host = "localhost"
+		}
+		l.add(destination{field: "connString", host: host, port: port})
+	}
+
+	port := "5432"
+	if p := params.Get("port"); p != "" && !strings.Contains(p, ",") {
+		port = p
+	} else if d.port != "" {
+		port = d.port
+	}
+
+	// url.Parse doesn't split multiple hosts, so they are separated here.
+	if strings.Contains(d.host, ",") {
+		for _, hp := range strings.Split(d.host, ",") {
+			add(splitHostPort(hp, "5432"))
+		}
+	} else if d.host != "" {
+		add(d.host, port)
+	}
+
+	for _, key := range []string{"host", "hostaddr"} {
+		for _, value := range params[key] {
+			if err := templatedHost(value); err != nil {
+				l = destinationList{}
+				l.fail("connString", err)
+				return l
+			}
+			for _, host := range strings.Split(value, ",") {
+				add(host, port)
+			}
+		}
+	}
+
+	if len(l.dests) == 0 {
+		add("", port)
+	}
+	return l
+}
+
+func (h PostgresqlHostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h PostgresqlHostInfo) hostURL() string {
+	return h.ConnStr
+}
+
+func (h PostgresqlHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
 
-	return hashStr
+func (h PostgresqlHostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyPositional},
+		// NOTE: The legacy PostgreSQL host hash included the Type field as written, which may be empty, while the
+		// canonical hash uses the resolved host type.  Each encoding uses only one of these two entries.
+		{"type", h.Type, legacyOnly},
+		{"type", h.HostType(), legacyOmitted},
+		{"connString", h.ConnStr, legacyPositional},
+	}
 }
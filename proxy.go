@@ -0,0 +1,186 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+type ProxyInfo struct {
+	URL      string   `json:"url"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	NoProxy  []string `json:"noProxy"`
+}
+
+// ProxyFunc returns a function for use as http.Transport.Proxy, which sends requests through the proxy
+// unless the request's host matches an entry in the noProxy list.  The settings must already be resolved.
+// A nil *ProxyInfo returns a nil function, which makes requests directly.
+//
+// NoProxy entries have the same form as the NO_PROXY environment variable: a host name, which also matches
+// its subdomains, an IP address, a CIDR range, any of these followed by a port, or "*" to match all hosts.
+func (p *ProxyInfo) ProxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	for _, v := range []string{p.URL, p.Username, p.Password} {
+		if hasTemplate(v) {
+			return nil, errors.New("proxy settings must be resolved before building a proxy function")
+		}
+	}
+
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: [%s]", proxyURL.Scheme)
+	}
+	if p.Username != "" {
+		proxyURL.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	matchers := make([]noProxyMatcher, 0, len(p.NoProxy))
+	for _, entry := range p.NoProxy {
+		m, err := parseNoProxy(entry)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		host, port := req.URL.Hostname(), req.URL.Port()
+		if port == "" {
+			port = defaultPort(req.URL.Scheme)
+		}
+		for _, m := range matchers {
+			if m.matches(host, port) {
+				return nil, nil
+			}
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+func (p *ProxyInfo) variables() []string {
+	if p == nil {
+		return nil
+	}
+
+	var results []string
+	for _, v := range []string{p.URL, p.Username, p.Password} {
+		results = append(results, extractVariables(v)...)
+	}
+	return results
+}
+
+func (p *ProxyInfo) clone() *ProxyInfo {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	c.NoProxy = slices.Clone(p.NoProxy)
+	return &c
+}
+
+// redacted returns a copy of the proxy settings, with the passwords masked.
+func (p *ProxyInfo) redacted() *ProxyInfo {
+	if p == nil {
+		return nil
+	}
+	r := *p
+	r.URL = redactURL(r.URL, false)
+	r.Password = redactValue(r.Password)
+	return &r
+}
+
+func (p *ProxyInfo) hashFields() []hashField {
+	if p == nil {
+		return nil
+	}
+
+	return []hashField{
+		{"proxy.url", p.URL, legacyExtension},
+		{"proxy.username", p.Username, legacyExtension},
+		{"proxy.password", p.Password, legacyExtension},
+		{"proxy.noProxy", p.NoProxy, legacyExtension},
+	}
+}
+
+type noProxyMatcher struct {
+	all    bool
+	domain string
+	ip     net.IP
+	cidr   *net.IPNet
+	port   string
+}
+
+func parseNoProxy(entry string) (noProxyMatcher, error) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+	if entry == "*" {
+		return noProxyMatcher{all: true}, nil
+	}
+
+	if _, cidr, err := net.ParseCIDR(entry); err == nil {
+		return noProxyMatcher{cidr: cidr}, nil
+	}
+
+	host, port := entry, ""
+	if h, p, err := net.SplitHostPort(entry); err == nil {
+		host, port = h, p
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return noProxyMatcher{ip: ip, port: port}, nil
+	}
+
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "*"), ".")
+	if host == "" {
+		return noProxyMatcher{}, fmt.Errorf("invalid noProxy entry: [%s]", entry)
+	}
+	return noProxyMatcher{domain: host, port: port}, nil
+}
+
+func (m noProxyMatcher) matches(host, port string) bool {
+	if m.all {
+		return true
+	}
+	if m.port != "" && m.port != port {
+		return false
+	}
+
+	host = strings.ToLower(host)
+	switch {
+	case m.cidr != nil:
+		ip := net.ParseIP(host)
+		return ip != nil && m.cidr.Contains(ip)
+	case m.ip != nil:
+		ip := net.ParseIP(host)
+		return ip != nil && m.ip.Equal(ip)
+	default:
+		return host == m.domain || strings.HasSuffix(host, "."+m.domain)
+	}
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "https", "wss":
+		return "443"
+	case "http", "ws":
+		return "80"
+	default:
+		return ""
+	}
+}
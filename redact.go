@@ -0,0 +1,136 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"unicode"
+)
+
+// redactedValue replaces the literal parts of values that may contain secrets.
+const redactedValue = "****"
+
+// redactValue masks the literal parts of a value, while keeping any templates and a leading auth scheme visible,
+// such as "Bearer ****" or "Bearer {{AUTH_TOKEN}}".
+func redactValue(s string) string {
+	if s == "" {
+		return s
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range templateRegex.FindAllStringIndex(s, -1) {
+		sb.WriteString(redactLiteral(s[last:loc[0]], last == 0))
+		sb.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	sb.WriteString(redactLiteral(s[last:], last == 0))
+	return sb.String()
+}
+
+func redactLiteral(s string, start bool) string {
+	var scheme string
+	if start {
+		scheme = authSchemePattern.FindString(s)
+		s = s[len(scheme):]
+	}
+
+	// Separators between templates, such as the colon in "{{USERNAME}}:{{PASSWORD}}", are not secrets.
+	if strings.IndexFunc(s, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return scheme + s
+	}
+	return scheme + redactedValue
+}
+
+func redactMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	results := make(map[string]string, len(m))
+	for k, v := range m {
+		results[k] = redactValue(v)
+	}
+	return results
+}
+
+// redactURL masks the password in the user info of a URL or connection string, and its query parameter values.
+// If allQuery is false, only the password query parameter is masked.
+func redactURL(s string, allQuery bool) string {
+	if s == "" {
+		return s
+	}
+
+	if scheme, rest, ok := strings.Cut(s, "://"); ok {
+		end := len(rest)
+		if i := strings.IndexAny(rest, "/?#"); i >= 0 {
+			end = i
+		}
+		if at := strings.LastIndex(rest[:end], "@"); at >= 0 {
+			if user, password, ok := strings.Cut(rest[:at], ":"); ok {
+				rest = user + ":" + redactValue(password) + rest[at:]
+			}
+		}
+		s = scheme + "://" + rest
+	}
+
+	base, query, ok := strings.Cut(s, "?")
+	if !ok {
+		return s
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		if k, v, ok := strings.Cut(param, "="); ok && (allQuery || strings.EqualFold(k, "password")) {
+			params[i] = k + "=" + redactValue(v)
+		}
+	}
+
+	s = base + "?" + strings.Join(params, "&")
+	if hasFragment {
+		s += "#" + fragment
+	}
+	return s
+}
+
+// redactedString formats the redacted host as JSON, including its name and type.
+func redactedString(host HostInfo) string {
+	return formatData(redactedData(host))
+}
+
+// redactedLogValue returns the redacted host as a group, including its name and type.
+func redactedLogValue(host HostInfo) slog.Value {
+	return dataLogValue(redactedData(host))
+}
+
+func redactedData(host HostInfo) map[string]any {
+	return hostData(asBuiltin(host).redacted())
+}
+
+func formatData(data map[string]any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		// Manifest data is always encodable, so this should never happen.
+		return err.Error()
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func dataLogValue(data map[string]any) slog.Value {
+	attrs := make([]slog.Attr, 0, len(data))
+	for _, k := range sortedKeys(data) {
+		if m, ok := data[k].(map[string]any); ok {
+			attrs = append(attrs, slog.Attr{Key: k, Value: dataLogValue(m)})
+		} else {
+			attrs = append(attrs, slog.Any(k, data[k]))
+		}
+	}
+	return slog.GroupValue(attrs...)
+}
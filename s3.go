@@ -0,0 +1,185 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const (
+	HostTypeS3 string = "s3"
+)
+
+// S3HostInfo is a host for a bucket in Amazon S3, or in a service that is compatible with S3, such as MinIO.
+type S3HostInfo struct {
+	Name string `json:"-"`
+	Type string `json:"type"`
+
+	// Endpoint is the URL of a service that is compatible with S3, such as "http://localhost:9000" for MinIO.
+	// If not set, the Amazon S3 endpoint for the region is used.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	Region string `json:"region,omitempty"`
+	Bucket string `json:"bucket"`
+
+	// PathStyle puts the bucket name in the path of request URLs, instead of in the host name.
+	// It is usually needed for MinIO.
+	PathStyle bool `json:"pathStyle,omitempty"`
+
+	AccessKeyID     string `json:"accessKeyId,omitempty"`
+	SecretAccessKey string `json:"secretAccessKey,omitempty"`
+	SessionToken    string `json:"sessionToken,omitempty"`
+}
+
+func (h S3HostInfo) HostName() string {
+	return h.Name
+}
+
+func (S3HostInfo) HostType() string {
+	return HostTypeS3
+}
+
+func (h S3HostInfo) GetVariables() []string {
+	return extractAllVariables(h.Endpoint, h.AccessKeyID, h.SecretAccessKey, h.SessionToken)
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h S3HostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h S3HostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings, with the secret access key and session token masked.
+func (h S3HostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging, with the secret access key and session token masked.
+func (h S3HostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+func (h S3HostInfo) redacted() HostInfo {
+	h.Endpoint = redactURL(h.Endpoint, true)
+	h.SecretAccessKey = redactValue(h.SecretAccessKey)
+	h.SessionToken = redactValue(h.SessionToken)
+	return h
+}
+
+// BucketURL returns the base URL for requests to objects in the bucket, ending with a trailing slash,
+// such as "https://documents.s3.us-east-1.amazonaws.com/" or "http://localhost:9000/documents/".
+func (h S3HostInfo) BucketURL() string {
+	endpoint := h.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + h.Region + ".amazonaws.com"
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	if h.PathStyle {
+		return endpoint + "/" + h.Bucket + "/"
+	}
+	scheme, rest, ok := strings.Cut(endpoint, "://")
+	if !ok {
+		return endpoint + "/" + h.Bucket + "/"
+	}
+	return scheme + "://" + h.Bucket + "." + rest + "/"
+}
+
+// Validate checks the host settings with the default validation options.
+func (h S3HostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h S3HostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if h.Bucket == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "bucket"),
+			Message:  "bucket is required",
+		})
+	}
+	if h.Endpoint == "" && h.Region == "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "region"),
+			Message:  "region is required when no endpoint is given",
+		})
+	}
+	if (h.AccessKeyID == "") != (h.SecretAccessKey == "") {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name),
+			Message:  "accessKeyId and secretAccessKey must be given together",
+		})
+	}
+	if !h.PathStyle && strings.Contains(h.Bucket, ".") && !strings.HasPrefix(h.Endpoint, "http://") {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityWarning,
+			Path:     jsonPointer("hosts", h.Name, "bucket"),
+			Message:  "bucket names that contain dots don't match TLS certificates for virtual-hosted URLs, so set pathStyle",
+		})
+	}
+	return append(diags, findSecrets(h, opts)...)
+}
+
+// Clone returns a copy of the host.
+func (h S3HostInfo) Clone() HostInfo {
+	return h
+}
+
+// Equal reports whether the other host is an s3 host with the same settings.
+func (h S3HostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h S3HostInfo) secretFields() []secretField {
+	fields := urlSecretFields([]string{"endpoint"}, h.Endpoint)
+	return append(fields,
+		secretField{[]string{"accessKeyId"}, h.AccessKeyID, "access key id", ""},
+		secretField{[]string{"secretAccessKey"}, h.SecretAccessKey, "secret access key", "secret access key"},
+		secretField{[]string{"sessionToken"}, h.SessionToken, "session token", "session token"},
+	)
+}
+
+func (h S3HostInfo) destinations() destinationList {
+	var l destinationList
+	if h.Endpoint != "" {
+		l.addURL("endpoint", h.BucketURL())
+	} else {
+		l.addURL("region", h.BucketURL())
+	}
+	return l
+}
+
+func (h S3HostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h S3HostInfo) hostURL() string {
+	return h.BucketURL()
+}
+
+func (h S3HostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+func (h S3HostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"endpoint", h.Endpoint, legacyExtension},
+		{"region", h.Region, legacyExtension},
+		{"bucket", h.Bucket, legacyExtension},
+		{"pathStyle", h.PathStyle, legacyExtension},
+		{"accessKeyId", h.AccessKeyID, legacyExtension},
+		{"secretAccessKey", h.SecretAccessKey, legacyExtension},
+		{"sessionToken", h.SessionToken, legacyExtension},
+	}
+}
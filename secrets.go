@@ -0,0 +1,210 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// secretField is a value in a host that could contain a hard-coded secret.
+type secretField struct {
+	path  []string
+	value string
+	// variable is the suffix of the suggested variable name, after the host name.
+	variable string
+	// kind describes the secret, for fields that hold secrets, so that any literal value is reported.
+	kind string
+}
+
+var (
+	// sensitiveNamePattern matches the names of headers, query parameters and metadata that hold secrets.
+	sensitiveNamePattern = regexp.MustCompile(`(?i)auth|key|token|secret|password|cookie|credential|signature`)
+	authSchemePattern    = regexp.MustCompile(`^(?i:bearer|basic|token|apikey)\s+`)
+)
+
+// namedSecretField returns a field for a header, query parameter or metadata value.
+// The value is treated as a secret if its name suggests one, such as X-Api-Key.
+func namedSecretField(path []string, name, value string) secretField {
+	var kind string
+	if sensitiveNamePattern.MatchString(name) {
+		kind = "secret"
+	}
+	return secretField{path, value, name, kind}
+}
+
+// detect returns a description of the hard-coded secret in the literal parts of the field, outside of any templates
+// and a leading auth scheme, or an empty string if there is none.  certain is false for high-entropy strings in
+// fields that are not known to hold secrets, since they might not be secrets.
+func (f secretField) detect() (kind string, certain bool) {
+	value := f.value[len(authSchemePattern.FindString(f.value)):]
+	literal := strings.TrimSpace(templateRegex.ReplaceAllString(value, " "))
+	switch {
+	case literal == "":
+		return "", false
+	case knownSecretKind(literal) != "":
+		return "hard-coded " + knownSecretKind(literal), true
+	case f.kind != "":
+		return "hard-coded " + f.kind, true
+	case hasHighEntropyToken(literal):
+		return "possible hard-coded secret", false
+	default:
+		return "", false
+	}
+}
+
+var knownSecretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"Anthropic API key", regexp.MustCompile(`^sk-ant-[A-Za-z0-9_\-]{20,}`)},
+	{"OpenAI API key", regexp.MustCompile(`^sk-(?:proj-)?[A-Za-z0-9_\-]{20,}`)},
+	{"Hugging Face token", regexp.MustCompile(`^hf_[A-Za-z0-9]{30,}`)},
+	{"GitHub token", regexp.MustCompile(`^(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"AWS access key ID", regexp.MustCompile(`^(?:AKIA|ASIA)[A-Z0-9]{16}`)},
+}
+
+// findSecrets scans the literal parts of the host's values, outside of any templates, for hard-coded secrets.
+// Known token formats, and fields that hold secrets, are reported as warnings, or as errors
+// with the production policy.  Other high-entropy strings are always reported as warnings, since they
+// might not be secrets.
+func findSecrets(host HostInfo, opts ValidationOptions) []Diagnostic {
+	name := host.HostName()
+
+	var diags []Diagnostic
+	for _, f := range asBuiltin(host).secretFields() {
+		kind, certain := f.detect()
+		if kind == "" {
+			continue
+		}
+
+		severity := SeverityWarning
+		if certain && opts.Production {
+			severity = SeverityError
+		}
+
+		variable := suggestVariableName(name, f.variable)
+		diags = append(diags, Diagnostic{
+			Severity: severity,
+			Path:     jsonPointer(append([]string{"hosts", name}, f.path...)...),
+			Message:  fmt.Sprintf("%s found, move it into a variable and use a template such as {{%s}} instead", kind, variable),
+		})
+	}
+	return diags
+}
+
+// secretVariables returns the variables used in fields of the host that hold secrets.  These are the fields
+// that always hold a secret, along with headers, query parameters and metadata whose names suggest a secret.
+func secretVariables(host HostInfo) []string {
+	var values []string
+	for _, f := range asBuiltin(host).secretFields() {
+		if f.kind != "" {
+			values = append(values, f.value)
+		}
+	}
+	return extractAllVariables(values...)
+}
+
+// urlSecretFields returns the parts of a URL or connection string that could contain secrets:
+// the password in the user info, and the query parameter values.
+// A password query parameter, as used by connection strings, is treated the same as a password in the user info.
+// If the value can't be parsed as a URL, it is checked as a whole, so that known token formats are still found.
+func urlSecretFields(path []string, value string) []secretField {
+	if value == "" {
+		return nil
+	}
+
+	// Templates aren't valid in every part of a URL, so they are replaced with placeholders while parsing,
+	// and restored in the values that are returned.
+	var restore []string
+	placeholders := templateRegex.ReplaceAllStringFunc(value, func(template string) string {
+		placeholder := fmt.Sprintf("template%dtemplate", len(restore)/2)
+		restore = append(restore, placeholder, template)
+		return placeholder
+	})
+	replacer := strings.NewReplacer(restore...)
+
+	u, err := url.Parse(placeholders)
+	if err != nil || u.Scheme == "" {
+		return []secretField{{path, value, path[len(path)-1], ""}}
+	}
+
+	var fields []secretField
+	if password, ok := u.User.Password(); ok {
+		fields = append(fields, secretField{path, replacer.Replace(password), "password", "password"})
+	}
+
+	query := u.Query()
+	for _, k := range sortedKeys(query) {
+		var kind string
+		if strings.EqualFold(k, "password") {
+			kind = "password"
+		}
+		for _, v := range query[k] {
+			fields = append(fields, secretField{path, replacer.Replace(v), replacer.Replace(k), kind})
+		}
+	}
+
+	return fields
+}
+
+func knownSecretKind(literal string) string {
+	for _, token := range secretTokens(literal) {
+		for _, p := range knownSecretPatterns {
+			if p.pattern.MatchString(token) {
+				return p.kind
+			}
+		}
+	}
+	return ""
+}
+
+// hasHighEntropyToken reports whether any part of the literal is long and random enough to likely be a secret,
+// such as a base64 or hex encoded key.
+func hasHighEntropyToken(literal string) bool {
+	for _, token := range secretTokens(literal) {
+		if len(token) < 20 || strings.Contains(token, "://") {
+			continue
+		}
+		if !strings.ContainsAny(token, "0123456789") || strings.Trim(token, "0123456789") == "" {
+			continue
+		}
+
+		threshold := 4.0
+		if strings.Trim(strings.ToLower(token), "0123456789abcdef-") == "" {
+			// Hex strings have a smaller alphabet, and so lower entropy per character.
+			threshold = 3.0
+		}
+		if shannonEntropy(token) >= threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func secretTokens(literal string) []string {
+	return strings.FieldsFunc(literal, func(r rune) bool {
+		return strings.ContainsRune(" \t\r\n,;&'\"", r)
+	})
+}
+
+// shannonEntropy returns the entropy of the string, in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var entropy float64
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+)
+
+// sigV4Transport signs each request with AWS Signature Version 4.
+type sigV4Transport struct {
+	auth *AuthInfo
+	base http.RoundTripper
+}
+
+func (t *sigV4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the original request.
+	r := req.Clone(req.Context())
+	if err := t.auth.SignAWSV4(r, time.Now()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(r)
+}
+
+// SignAWSV4 signs the request in place with AWS Signature Version 4, using the aws-sigv4 auth settings,
+// which must already be resolved.  The request body, if any, is read to compute the payload hash and
+// is then replaced so that it can still be sent.
+func (a *AuthInfo) SignAWSV4(req *http.Request, signingTime time.Time) error {
+	if a == nil || a.Type != AuthTypeAWSSigV4 {
+		return fmt.Errorf("auth type must be %s to sign a request", AuthTypeAWSSigV4)
+	}
+
+	payload, err := readRequestBody(req)
+	if err != nil {
+		return fmt.Errorf("failed to read request body for signing: %w", err)
+	}
+	payloadHash := sha256Hex(payload)
+
+	t := signingTime.UTC()
+	amzDate := t.Format(sigV4TimeFormat)
+	date := t.Format(sigV4DateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	if a.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := sigV4CanonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4CanonicalURI(req.URL, a.Service != "s3"),
+		sigV4CanonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, a.Region, a.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretAccessKey), date)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, a.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, a.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+	return payload, nil
+}
+
+// sigV4CanonicalHeaders returns the signed header list and the canonical headers block.
+// The host, content type, and all x-amz-* headers are signed.
+func sigV4CanonicalHeaders(req *http.Request) (string, string) {
+	values := map[string]string{
+		"host": sigV4Host(req),
+	}
+	for name, vals := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(vals))
+			for i, v := range vals {
+				trimmed[i] = strings.Join(strings.Fields(v), " ")
+			}
+			values[lower] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := sortedKeys(values)
+
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(name)
+		sb.WriteByte(':')
+		sb.WriteString(values[name])
+		sb.WriteByte('\n')
+	}
+
+	return strings.Join(names, ";"), sb.String()
+}
+
+func sigV4Host(req *http.Request) string {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	// Default ports are not included when signing.
+	switch {
+	case req.URL.Scheme == "https" && strings.HasSuffix(host, ":443"):
+		host = strings.TrimSuffix(host, ":443")
+	case req.URL.Scheme == "http" && strings.HasSuffix(host, ":80"):
+		host = strings.TrimSuffix(host, ":80")
+	}
+	return host
+}
+
+// sigV4CanonicalURI returns the URI encoded path.  Services other than S3 normalize the path and encode it twice,
+// which is equivalent to encoding the path exactly as it is sent.
+func sigV4CanonicalURI(u *url.URL, doubleEncode bool) string {
+	path := u.EscapedPath()
+	if !doubleEncode {
+		path = u.Path
+	} else {
+		path = sigV4NormalizePath(path)
+	}
+	if path == "" {
+		return "/"
+	}
+	return sigV4Escape(path, true)
+}
+
+// sigV4NormalizePath removes empty and dot segments from the path, as required for services other than S3.
+// A trailing slash is kept.
+func sigV4NormalizePath(path string) string {
+	if path == "" {
+		return path
+	}
+
+	segments := strings.Split(path, "/")
+	results := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(results) > 0 {
+				results = results[:len(results)-1]
+			}
+		default:
+			results = append(results, segment)
+		}
+	}
+
+	normalized := "/" + strings.Join(results, "/")
+	if len(results) > 0 && strings.HasSuffix(path, "/") {
+		normalized += "/"
+	}
+	return normalized
+}
+
+// sigV4CanonicalQuery returns the encoded query parameters, sorted by encoded key and then by encoded value.
+// Sorting the joined pairs instead would put "a-b=1" before "a=1", because '-' sorts below '='.
+func sigV4CanonicalQuery(u *url.URL) string {
+	type pair struct{ key, value string }
+
+	query := u.Query()
+	pairs := make([]pair, 0, len(query))
+	for key, vals := range query {
+		k := sigV4Escape(key, false)
+		for _, v := range vals {
+			pairs = append(pairs, pair{k, sigV4Escape(v, false)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.key + "=" + p.value
+	}
+	return strings.Join(parts, "&")
+}
+
+// sigV4Escape percent-encodes every byte except the unreserved characters of RFC 3986,
+// and optionally the path separator.
+func sigV4Escape(s string, keepSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', keepSlash && c == '/':
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hexDigits[c>>4])
+			sb.WriteByte(hexDigits[c&0xf])
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
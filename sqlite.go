@@ -0,0 +1,179 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	HostTypeSQLite string = "sqlite"
+
+	// HostTypeLocalFile is an alias of HostTypeSQLite.  Hosts of either type are read as SQLiteHostInfo,
+	// and report HostTypeSQLite as their type.
+	HostTypeLocalFile string = "local-file"
+)
+
+// SQLiteMemoryPath is the path of an in-memory database, which is discarded when it is closed.
+const SQLiteMemoryPath = ":memory:"
+
+// SQLiteHostInfo is a host for a local SQLite database file, intended for development and tests.
+type SQLiteHostInfo struct {
+	Name string `json:"-"`
+	Type string `json:"type"`
+
+	// Path is the path of the database file, relative to the project, or SQLiteMemoryPath.
+	Path string `json:"path"`
+
+	// ReadOnly opens the database without write access.
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// WAL sets the journal mode of the database to write-ahead logging.
+	WAL bool `json:"wal,omitempty"`
+}
+
+func (h SQLiteHostInfo) HostName() string {
+	return h.Name
+}
+
+func (SQLiteHostInfo) HostType() string {
+	return HostTypeSQLite
+}
+
+// GetVariables returns nil, since the path can't contain templates.
+func (h SQLiteHostInfo) GetVariables() []string {
+	return nil
+}
+
+// Hash returns the hash of the host, using HashVersionDefault.
+func (h SQLiteHostInfo) Hash() string {
+	return h.HashWith(HashVersionDefault)
+}
+
+func (h SQLiteHostInfo) HashWith(version HashVersion) string {
+	return computeHash(version, h.hashFields())
+}
+
+// String returns the host settings.  A sqlite host has no credentials to mask.
+func (h SQLiteHostInfo) String() string {
+	return redactedString(h)
+}
+
+// LogValue returns the host settings for logging.
+func (h SQLiteHostInfo) LogValue() slog.Value {
+	return redactedLogValue(h)
+}
+
+// ResolvePath returns the absolute path of the database file, within the given project directory.
+// SQLiteMemoryPath is returned as it is.  An error is returned if the path is not allowed.
+func (h SQLiteHostInfo) ResolvePath(projectDir string) (string, error) {
+	if h.Path == SQLiteMemoryPath {
+		return h.Path, nil
+	}
+	if msg := h.validatePath(); msg != "" {
+		return "", fmt.Errorf("invalid path for host [%s]: %s", h.Name, msg)
+	}
+
+	dir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	return filepath.Join(dir, filepath.FromSlash(strings.ReplaceAll(h.Path, "\\", "/"))), nil
+}
+
+func (h SQLiteHostInfo) validatePath() string {
+	switch {
+	case h.Path == "":
+		return "path is required"
+	case h.Path == SQLiteMemoryPath:
+		return ""
+	case hasTemplate(h.Path):
+		return "path must not contain templates"
+	case strings.HasPrefix(h.Path, "file:"):
+		return "path must be a file path, not a URI"
+	}
+	return validateProjectPath("path", h.Path)
+}
+
+// Validate checks the host settings with the default validation options.
+func (h SQLiteHostInfo) Validate() []Diagnostic {
+	return h.validate(ValidationOptions{})
+}
+
+func (h SQLiteHostInfo) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	if msg := h.validatePath(); msg != "" {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "path"),
+			Message:  msg,
+		})
+	}
+
+	if h.ReadOnly && h.WAL {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "wal"),
+			Message:  "wal can't be used with readOnly, because setting the journal mode needs write access",
+		})
+	}
+
+	if opts.Production {
+		diags = append(diags, Diagnostic{
+			Severity: SeverityError,
+			Path:     jsonPointer("hosts", h.Name, "type"),
+			Message:  "sqlite hosts are only intended for development, and are not allowed by the policy",
+		})
+	}
+	return diags
+}
+
+// Clone returns a copy of the host.
+func (h SQLiteHostInfo) Clone() HostInfo {
+	return h
+}
+
+// Equal reports whether the other host is a sqlite host with the same settings.
+func (h SQLiteHostInfo) Equal(other HostInfo) bool {
+	return hostsEqual(h, other)
+}
+
+func (h SQLiteHostInfo) redacted() HostInfo {
+	return h
+}
+
+func (h SQLiteHostInfo) secretFields() []secretField {
+	return nil
+}
+
+// destinations returns no destinations, since the database is a local file.
+func (h SQLiteHostInfo) destinations() destinationList {
+	return destinationList{}
+}
+
+func (h SQLiteHostInfo) tlsInfo() *TLSInfo {
+	return nil
+}
+
+func (h SQLiteHostInfo) hostURL() string {
+	return h.Path
+}
+
+func (h SQLiteHostInfo) httpHost() (HTTPHostInfo, bool) {
+	return HTTPHostInfo{}, false
+}
+
+func (h SQLiteHostInfo) hashFields() []hashField {
+	return []hashField{
+		{"name", h.Name, legacyExtension},
+		{"type", h.HostType(), legacyExtension},
+		{"path", h.Path, legacyExtension},
+		{"readOnly", h.ReadOnly, legacyExtension},
+		{"wal", h.WAL, legacyExtension},
+	}
+}
@@ -0,0 +1,174 @@
+package manifest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestAuthInfo_EquivalentToHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		withAuth   manifest.HTTPHostInfo
+		withHeader manifest.HTTPHostInfo
+	}{
+		{
+			name: "bearer",
+			withAuth: manifest.HTTPHostInfo{
+				Auth: &manifest.AuthInfo{Type: manifest.AuthTypeBearer, Token: "{{AUTH_TOKEN}}"},
+			},
+			withHeader: manifest.HTTPHostInfo{
+				Headers: map[string]string{"Authorization": "Bearer {{AUTH_TOKEN}}"},
+			},
+		},
+		{
+			name: "basic",
+			withAuth: manifest.HTTPHostInfo{
+				Auth: &manifest.AuthInfo{Type: manifest.AuthTypeBasic, Username: "{{USERNAME}}", Password: "{{PASSWORD}}"},
+			},
+			withHeader: manifest.HTTPHostInfo{
+				Headers: map[string]string{"Authorization": "Basic {{base64(USERNAME:PASSWORD)}}"},
+			},
+		},
+		{
+			name: "apiKey in header",
+			withAuth: manifest.HTTPHostInfo{
+				Auth: &manifest.AuthInfo{Type: manifest.AuthTypeAPIKey, KeyName: "X-API-Key", In: manifest.APIKeyInHeader, Value: "{{API_KEY}}"},
+			},
+			withHeader: manifest.HTTPHostInfo{
+				Headers: map[string]string{"X-API-Key": "{{API_KEY}}"},
+			},
+		},
+		{
+			name: "apiKey in query",
+			withAuth: manifest.HTTPHostInfo{
+				Auth: &manifest.AuthInfo{Type: manifest.AuthTypeAPIKey, KeyName: "api_token", In: manifest.APIKeyInQuery, Value: "{{API_TOKEN}}"},
+			},
+			withHeader: manifest.HTTPHostInfo{
+				QueryParameters: map[string]string{"api_token": "{{API_TOKEN}}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, h := tt.withAuth.EffectiveHeaders(), tt.withHeader.EffectiveHeaders(); !reflect.DeepEqual(a, h) {
+				t.Errorf("Expected headers: %v, but got: %v", h, a)
+			}
+			if a, h := tt.withAuth.EffectiveQueryParameters(), tt.withHeader.EffectiveQueryParameters(); !reflect.DeepEqual(a, h) {
+				t.Errorf("Expected query parameters: %v, but got: %v", h, a)
+			}
+			if a, h := tt.withAuth.GetVariables(), tt.withHeader.GetVariables(); !reflect.DeepEqual(a, h) {
+				t.Errorf("Expected variables: %v, but got: %v", h, a)
+			}
+		})
+	}
+}
+
+func TestAuthInfo_OverridesHeader(t *testing.T) {
+	host := manifest.HTTPHostInfo{
+		Headers: map[string]string{
+			"authorization": "Bearer {{OLD_TOKEN}}",
+			"X-Other":       "value",
+		},
+		Auth: &manifest.AuthInfo{Type: manifest.AuthTypeBearer, Token: "{{AUTH_TOKEN}}"},
+	}
+
+	expected := map[string]string{
+		"Authorization": "Bearer {{AUTH_TOKEN}}",
+		"X-Other":       "value",
+	}
+	if actual := host.EffectiveHeaders(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected headers: %v, but got: %v", expected, actual)
+	}
+}
+
+func TestValidateManifest_AuthOverridesHeaderWarning(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"my-api": {
+				"baseUrl": "https://api.example.com/",
+				"headers": {
+					"Authorization": "Bearer {{OLD_TOKEN}}"
+				},
+				"auth": {
+					"type": "bearer",
+					"token": "{{AUTH_TOKEN}}"
+				}
+			}
+		}
+	}`)
+
+	diags, err := manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityWarning || diags[0].Path != "/hosts/my-api/headers/Authorization" {
+		t.Errorf("Expected a single warning for the overridden header, but got: %v", diags)
+	}
+}
+
+func TestAuthTransport_Static(t *testing.T) {
+	var header http.Header
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		query = r.URL.RawQuery
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		auth  *manifest.AuthInfo
+		check func(t *testing.T)
+	}{
+		{
+			name: "bearer",
+			auth: &manifest.AuthInfo{Type: manifest.AuthTypeBearer, Token: "my-token"},
+			check: func(t *testing.T) {
+				if v := header.Get("Authorization"); v != "Bearer my-token" {
+					t.Errorf("Expected authorization: %q, but got: %q", "Bearer my-token", v)
+				}
+			},
+		},
+		{
+			name: "basic",
+			auth: &manifest.AuthInfo{Type: manifest.AuthTypeBasic, Username: "alice", Password: "secret"},
+			check: func(t *testing.T) {
+				if v := header.Get("Authorization"); v != "Basic YWxpY2U6c2VjcmV0" {
+					t.Errorf("Expected authorization: %q, but got: %q", "Basic YWxpY2U6c2VjcmV0", v)
+				}
+			},
+		},
+		{
+			name: "apiKey in query",
+			auth: &manifest.AuthInfo{Type: manifest.AuthTypeAPIKey, KeyName: "api_token", In: manifest.APIKeyInQuery, Value: "my key"},
+			check: func(t *testing.T) {
+				if query != "api_token=my+key&page=2" {
+					t.Errorf("Expected query: %q, but got: %q", "api_token=my+key&page=2", query)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := tt.auth.Transport(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{Transport: transport}
+			resp, err := client.Get(server.URL + "/?page=2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			tt.check(t)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package manifest_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestHostInfo_Clone(t *testing.T) {
+	original := manifest.HTTPHostInfo{
+		Name:            "my-api",
+		BaseURL:         "https://api.example.com/",
+		Headers:         map[string]string{"X-API-Key": "{{API_KEY}}"},
+		QueryParameters: map[string]string{"format": "json"},
+		Retry:           &manifest.RetryInfo{MaxAttempts: 3, Backoff: &manifest.BackoffInfo{Multiplier: 2}, RetryOn: []int{429}},
+		Auth:            &manifest.AuthInfo{Type: manifest.AuthTypeOAuth2ClientCredentials, Scopes: []string{"read"}},
+		Proxy:           &manifest.ProxyInfo{URL: "http://proxy.example.com:3128", NoProxy: []string{"localhost"}},
+	}
+	expectedHash := original.Hash()
+
+	clone := original.Clone().(manifest.HTTPHostInfo)
+	if !clone.Equal(original) {
+		t.Errorf("Expected the clone to equal the original, but got: %v", clone)
+	}
+
+	clone.Headers["X-API-Key"] = "changed"
+	clone.QueryParameters["format"] = "xml"
+	clone.Retry.Backoff.Multiplier = 3
+	clone.Retry.RetryOn[0] = 503
+	clone.Auth.Scopes[0] = "write"
+	clone.Proxy.NoProxy[0] = "example.com"
+
+	if actualHash := original.Hash(); actualHash != expectedHash {
+		t.Errorf("Expected the original to be unchanged by changes to the clone, but got: %v", original)
+	}
+	if clone.Equal(original) {
+		t.Error("Expected the changed clone not to equal the original")
+	}
+}
+
+func TestHostInfo_Equal(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     manifest.HostInfo
+		expected bool
+	}{
+		{
+			name:     "unset and empty maps",
+			a:        manifest.HTTPHostInfo{Name: "my-api", BaseURL: "https://api.example.com/"},
+			b:        manifest.HTTPHostInfo{Name: "my-api", Type: manifest.HostTypeHTTP, BaseURL: "https://api.example.com/", Headers: map[string]string{}},
+			expected: true,
+		},
+		{
+			name:     "different headers",
+			a:        manifest.HTTPHostInfo{Name: "my-api", Headers: map[string]string{"X-API-Key": "{{API_KEY}}"}},
+			b:        manifest.HTTPHostInfo{Name: "my-api", Headers: map[string]string{"X-API-Key": "{{OTHER_KEY}}"}},
+			expected: false,
+		},
+		{
+			name:     "different names",
+			a:        manifest.DgraphHostInfo{Name: "a", GrpcTarget: "localhost:9080"},
+			b:        manifest.DgraphHostInfo{Name: "b", GrpcTarget: "localhost:9080"},
+			expected: false,
+		},
+		{
+			name:     "different types",
+			a:        manifest.PostgresqlHostInfo{Name: "my-host"},
+			b:        manifest.DgraphHostInfo{Name: "my-host"},
+			expected: false,
+		},
+		{
+			name:     "nil",
+			a:        manifest.PostgresqlHostInfo{Name: "my-host"},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.a.Equal(tt.b); actual != tt.expected {
+				t.Errorf("Expected equal: %v, but got: %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHostInfo_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		host manifest.HostInfo
+		path string
+	}{
+		{"http without url", manifest.HTTPHostInfo{Name: "my-api"}, "/hosts/my-api"},
+		{"http base url without trailing slash", manifest.HTTPHostInfo{Name: "my-api", BaseURL: "https://api.example.com/v1"}, "/hosts/my-api/baseUrl"},
+		{"postgresql without connection string", manifest.PostgresqlHostInfo{Name: "my-database"}, "/hosts/my-database/connString"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.host.Validate()
+			if len(diags) != 1 || diags[0].Severity != manifest.SeverityError || diags[0].Path != tt.path {
+				t.Errorf("Expected a single error at %s, but got: %v", tt.path, diags)
+			}
+		})
+	}
+
+	// Hard-coded secrets are only errors with the production policy.
+	dgraph := manifest.DgraphHostInfo{Name: "my-dgraph", GrpcTarget: "localhost:9080", Key: "abc123"}
+	if diags := dgraph.Validate(); len(diags) != 1 || diags[0].Severity != manifest.SeverityWarning || diags[0].Path != "/hosts/my-dgraph/key" {
+		t.Errorf("Expected a single warning at %s, but got: %v", "/hosts/my-dgraph/key", diags)
+	}
+
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+	for _, host := range m.Hosts {
+		if diags := host.Validate(); len(diags) != 0 {
+			t.Errorf("Expected no diagnostics for host %s, but got: %v", host.HostName(), diags)
+		}
+	}
+}
+
+func TestGetHost(t *testing.T) {
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	if h, err := manifest.GetHost[manifest.PostgresqlHostInfo](&m, "neon"); err != nil {
+		t.Error(err)
+	} else if h.Name != "neon" {
+		t.Errorf("Expected host: %s, but got: %s", "neon", h.Name)
+	}
+
+	if h, err := manifest.GetHost[manifest.HostInfo](&m, "neon"); err != nil {
+		t.Error(err)
+	} else if h.HostType() != manifest.HostTypePostgresql {
+		t.Errorf("Expected host type: %s, but got: %s", manifest.HostTypePostgresql, h.HostType())
+	}
+
+	if _, err := manifest.GetHost[manifest.HTTPHostInfo](&m, "neon"); !errors.Is(err, manifest.ErrHostTypeMismatch) {
+		t.Errorf("Expected error: %v, but got: %v", manifest.ErrHostTypeMismatch, err)
+	}
+
+	if _, err := manifest.GetHost[manifest.HTTPHostInfo](&m, "no-such-host"); !errors.Is(err, manifest.ErrHostNotFound) {
+		t.Errorf("Expected error: %v, but got: %v", manifest.ErrHostNotFound, err)
+	}
+}
+
+// customHost is a host type from outside the manifest package.
+type customHost struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+func (h customHost) HostName() string                     { return h.Name }
+func (h customHost) HostType() string                     { return "custom" }
+func (h customHost) GetVariables() []string               { return nil }
+func (h customHost) Hash() string                         { return h.HashWith(manifest.HashVersionDefault) }
+func (h customHost) HashWith(manifest.HashVersion) string { return h.Name }
+func (h customHost) String() string                       { return h.Name }
+func (h customHost) LogValue() slog.Value                 { return slog.StringValue(h.Name) }
+func (h customHost) Clone() manifest.HostInfo             { return h }
+func (h customHost) Equal(other manifest.HostInfo) bool   { return h == other }
+func (h customHost) Validate() []manifest.Diagnostic      { return nil }
+
+func TestHostInfo_OutsideType(t *testing.T) {
+	m := manifest.HypermodeManifest{
+		Hosts: map[string]manifest.HostInfo{
+			"my-custom": customHost{Name: "my-custom", Secret: "hunter2"},
+		},
+	}
+
+	expected := `{"collections":{},"hosts":{"my-custom":{"name":"my-custom","type":"custom"}},"models":{},"version":0}`
+	if actual := m.String(); actual != expected {
+		t.Errorf("Expected the settings of the custom host to be hidden: %s, but got: %s", expected, actual)
+	}
+
+	violations := manifest.CheckPolicy(m, manifest.Policy{})
+	if len(violations) != 1 || violations[0].Path != "/hosts/my-custom/type" {
+		t.Errorf("Expected the custom host to fail the policy check, but got: %v", violations)
+	}
+
+	for _, d := range manifest.Lint(&m) {
+		if d.Rule != manifest.LintRuleUnusedHost {
+			t.Errorf("Expected only the unused host to be reported for the custom host, but got: %v", d)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package manifest_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestReadManifest_InvalidTimeout(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"my-api": {
+				"baseUrl": "https://api.example.com/",
+				"timeout": "thirty seconds"
+			}
+		}
+	}`)
+
+	if _, err := manifest.ReadManifest(content); err == nil {
+		t.Error("Expected an error reading a manifest with an invalid timeout")
+	}
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with an invalid timeout")
+	}
+}
+
+func TestHttpHostInfo_MarshalTimeout(t *testing.T) {
+	host := manifest.HTTPHostInfo{Type: manifest.HostTypeHTTP, BaseURL: "https://api.example.com/"}
+	data, err := json.Marshal(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"timeout"`) {
+		t.Errorf("Expected an unset timeout to be omitted, but got: %s", data)
+	}
+
+	host.Timeout = manifest.Duration(30 * time.Second)
+	data, err = json.Marshal(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"timeout":"30s"`) {
+		t.Errorf("Expected the timeout to be written as a duration string, but got: %s", data)
+	}
+}
+
+func TestValidateManifest_InvalidRetry(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"my-api": {
+				"baseUrl": "https://api.example.com/",
+				"retry": {
+					"maxAttempts": 0,
+					"retryOn": [42]
+				}
+			}
+		}
+	}`)
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with an invalid retry policy")
+	}
+}
+
+func TestRetryInfo_ShouldRetry(t *testing.T) {
+	var none *manifest.RetryInfo
+	if none.ShouldRetry(503) {
+		t.Error("Expected no retries without a retry policy")
+	}
+
+	retry := &manifest.RetryInfo{MaxAttempts: 3}
+	if !retry.ShouldRetry(503) || retry.ShouldRetry(500) {
+		t.Errorf("Expected the default status codes to be retried: %v", manifest.DefaultRetryOn)
+	}
+
+	retry.RetryOn = []int{500}
+	if !retry.ShouldRetry(500) || retry.ShouldRetry(503) {
+		t.Error("Expected only the configured status codes to be retried")
+	}
+}
+
+func TestRetryInfo_Delay(t *testing.T) {
+	retry := &manifest.RetryInfo{
+		MaxAttempts: 5,
+		Backoff: &manifest.BackoffInfo{
+			Initial:    manifest.Duration(500 * time.Millisecond),
+			Max:        manifest.Duration(3 * time.Second),
+			Multiplier: 2,
+		},
+	}
+
+	expected := []time.Duration{
+		500 * time.Millisecond,
+		1 * time.Second,
+		2 * time.Second,
+		3 * time.Second,
+	}
+	for i, want := range expected {
+		if got := retry.Delay(i + 1); got != want {
+			t.Errorf("Expected delay for attempt %d: %v, but got: %v", i+1, want, got)
+		}
+	}
+}
+
+func TestHttpHostInfo_HashWithPolicies(t *testing.T) {
+	host := manifest.HTTPHostInfo{
+		Name:     "my-host",
+		Endpoint: "https://example.com/api",
+		BaseURL:  "https://example.com/api",
+		Headers: map[string]string{
+			"Authorization": "Bearer {{API_TOKEN}}",
+		},
+		QueryParameters: map[string]string{
+			"api_token": "{{API_TOKEN}}",
+		},
+	}
+
+	// Same as TestHttpHostInfo_Hash, because no policies are set.
+	expectedHash := "897ba7738c819211a9291f402bbdda529aadd4f83107ee08157e72bc12e915ec"
+	if actualHash := host.Hash(); actualHash != expectedHash {
+		t.Errorf("Expected hash: %s, but got: %s", expectedHash, actualHash)
+	}
+
+	host.Timeout = manifest.Duration(30 * time.Second)
+	timeoutHash := host.Hash()
+	if timeoutHash == expectedHash {
+		t.Error("Expected hash to change when the timeout is set")
+	}
+
+	host.Retry = &manifest.RetryInfo{MaxAttempts: 3}
+	retryHash := host.Hash()
+	if retryHash == timeoutHash {
+		t.Error("Expected hash to change when the retry policy is set")
+	}
+
+	host.RateLimit = &manifest.RateLimitInfo{RequestsPerSecond: 5}
+	if host.Hash() == retryHash {
+		t.Error("Expected hash to change when the rate limit is set")
+	}
+}
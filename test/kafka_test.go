@@ -0,0 +1,130 @@
+package manifest_test
+
+import (
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestValidateManifest_KafkaBrokers(t *testing.T) {
+	tests := []struct {
+		brokers string
+		valid   bool
+	}{
+		{`["localhost:9092"]`, true},
+		{`["kafka-1.example.com:9093", "kafka-2.example.com:9093"]`, true},
+		{`["10.0.0.1:9092", "[::1]:9092"]`, true},
+		{`[]`, false},
+		{`["kafka.example.com"]`, false},
+		{`["kafka://kafka.example.com:9092"]`, false},
+		{`["kafka.example.com:9092", "kafka.example.com:9092"]`, false},
+		{`"kafka.example.com:9092"`, false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-kafka": {"type": "kafka", "brokers": ` + tt.brokers + `}}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.brokers, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateManifest_KafkaTLS(t *testing.T) {
+	tests := []struct {
+		tls   string
+		valid bool
+	}{
+		{`{}`, true},
+		{`{"caCert": "{{CA_CERT}}", "clientCert": "{{CLIENT_CERT}}", "clientKey": "{{CLIENT_KEY}}"}`, true},
+		{`{"clientKey": "{{CLIENT_KEY}}"}`, false},
+		{`{"verify": false}`, false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-kafka": {"type": "kafka", "brokers": ["kafka.example.com:9093"], "tls": ` + tt.tls + `}}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.tls, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateManifest_KafkaSASL(t *testing.T) {
+	tests := []struct {
+		sasl  string
+		valid bool
+	}{
+		{`{"mechanism": "SCRAM-SHA-256", "username": "{{KAFKA_USERNAME}}", "password": "{{KAFKA_PASSWORD}}"}`, true},
+		{`{"mechanism": "PLAIN", "username": "events", "password": "{{KAFKA_PASSWORD}}"}`, true},
+		{`{"mechanism": "GSSAPI", "username": "events", "password": "{{KAFKA_PASSWORD}}"}`, false},
+		{`{"mechanism": "PLAIN", "username": "events", "password": "hunter2"}`, false},
+		{`{"mechanism": "PLAIN", "username": "events"}`, false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-kafka": {"type": "kafka", "brokers": ["kafka.example.com:9093"], "tls": {}, "sasl": ` + tt.sasl + `}}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.sasl, tt.valid, err)
+		}
+	}
+}
+
+func TestKafkaHostInfo_Validate(t *testing.T) {
+	host := manifest.KafkaHostInfo{
+		Name:    "my-kafka",
+		Brokers: []string{"kafka.example.com:9092", "kafka.example.com"},
+		SASL:    &manifest.KafkaSASLInfo{Mechanism: manifest.KafkaSASLPlain, Username: "events", Password: "{{KAFKA_PASSWORD}}"},
+	}
+
+	expected := []struct {
+		path     string
+		severity manifest.Severity
+	}{
+		{"/hosts/my-kafka/brokers/1", manifest.SeverityError},
+		{"/hosts/my-kafka/sasl/mechanism", manifest.SeverityWarning},
+	}
+
+	diags := host.Validate()
+	if len(diags) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, but got: %v", len(expected), diags)
+	}
+	for i, d := range diags {
+		if d.Path != expected[i].path || d.Severity != expected[i].severity {
+			t.Errorf("Expected diagnostic at: %s with severity %v, but got: %v", expected[i].path, expected[i].severity, d)
+		}
+	}
+
+	// An empty tls object enables TLS, so it is not equal to a host without TLS.
+	withTLS := host.Clone().(manifest.KafkaHostInfo)
+	withTLS.TLS = &manifest.TLSInfo{}
+	if host.Equal(withTLS) {
+		t.Errorf("Expected a host with TLS to differ from one without")
+	}
+	if diags := withTLS.Validate(); len(diags) != 1 {
+		t.Errorf("Expected only the broker error once TLS is used, but got: %v", diags)
+	}
+}
+
+func TestCheckPolicy_Kafka(t *testing.T) {
+	m := manifest.HypermodeManifest{Hosts: map[string]manifest.HostInfo{
+		"my-kafka": manifest.KafkaHostInfo{
+			Name:    "my-kafka",
+			Brokers: []string{"kafka-1.example.com:9093", "kafka-2.internal:9093", "{{KAFKA_BROKER}}"},
+		},
+	}}
+	policy := manifest.Policy{AllowedDomains: []string{"*.example.com"}, AllowedPorts: []int{9093}}
+
+	expected := []string{
+		"/hosts/my-kafka/brokers",
+		"/hosts/my-kafka/brokers",
+	}
+
+	violations := manifest.CheckPolicy(m, policy)
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, but got: %v", len(expected), violations)
+	}
+	for i, v := range violations {
+		if v.Path != expected[i] {
+			t.Errorf("Expected violation at: %s, but got: %v", expected[i], v)
+		}
+	}
+}
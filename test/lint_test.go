@@ -0,0 +1,269 @@
+package manifest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+var lintManifest = []byte(`{
+  "models": {
+    "model-1": {
+      "sourceModel": "source-model-1",
+      "host": "endpoint-host",
+      "dedicated": true
+    },
+    "model-2": {
+      "sourceModel": "source-model-2",
+      "host": "copy-of-api"
+    }
+  },
+  "hosts": {
+    "endpoint-host": {
+      "endpoint": "https://models.example.com/path/to/model-1"
+    },
+    "my-api": {
+      "baseUrl": "https://api.example.com/v1/",
+      "headers": {
+        "Authorization": "Bearer abc123"
+      }
+    },
+    "copy-of-api": {
+      "baseUrl": "https://api.example.com/v1/",
+      "headers": {
+        "Authorization": "Bearer abc123"
+      }
+    }
+  },
+  "collections": {
+    "empty": {
+      "searchMethods": {}
+    }
+  }
+}`)
+
+type lintResult struct {
+	Rule string
+	Path string
+}
+
+func lintResults(diags []manifest.Diagnostic) []lintResult {
+	results := make([]lintResult, len(diags))
+	for i, d := range diags {
+		results[i] = lintResult{d.Rule, d.Path}
+	}
+	return results
+}
+
+func TestLintManifest(t *testing.T) {
+	diags, err := manifest.LintManifest(lintManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []lintResult{
+		{manifest.LintRulePreferBaseURL, "/hosts/endpoint-host/endpoint"},
+		{manifest.LintRuleDuplicateHost, "/hosts/my-api"},
+		{manifest.LintRuleUnusedHost, "/hosts/my-api"},
+		{manifest.LintRulePlaintextSecret, "/hosts/copy-of-api/headers/Authorization"},
+		{manifest.LintRulePlaintextSecret, "/hosts/my-api/headers/Authorization"},
+		{manifest.LintRuleDedicatedNonHypermode, "/models/model-1/dedicated"},
+		{manifest.LintRuleEmptySearchMethods, "/collections/empty"},
+	}
+	if actual := lintResults(diags); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+
+	// Functions can use the duplicate host by name, so it isn't removed automatically.
+	for _, d := range diags {
+		if d.Rule == manifest.LintRuleDuplicateHost && d.Fix != nil {
+			t.Errorf("Expected no fix for the duplicate host, but got: %+v", d.Fix)
+		}
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	diags, err := manifest.LintManifest(lintManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := manifest.ApplyFixes(lintManifest, diags...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.ReadManifest(content)
+	if err != nil {
+		t.Fatalf("Error reading fixed manifest: %v\n%s", err, content)
+	}
+
+	if model := m.Models["model-1"]; model.Path != "model-1" || model.Dedicated {
+		t.Errorf("Expected model-1 to have a path and not be dedicated, but got: %+v", model)
+	}
+	if host := m.Hosts["endpoint-host"].(manifest.HTTPHostInfo); host.BaseURL != "https://models.example.com/path/to/" || host.Endpoint != "" {
+		t.Errorf("Expected endpoint-host to use a base url, but got: %+v", host)
+	}
+	if model := m.Models["model-2"]; model.Host != "copy-of-api" {
+		t.Errorf("Expected model-2 to keep using copy-of-api, but got: %s", model.Host)
+	}
+	expectedHeader := "Bearer {{COPY_OF_API_AUTHORIZATION}}"
+	if actual := m.Hosts["copy-of-api"].(manifest.HTTPHostInfo).Headers["Authorization"]; actual != expectedHeader {
+		t.Errorf("Expected header: %s, but got: %s", expectedHeader, actual)
+	}
+
+	// The duplicate host is kept, since functions could use it.  After the secret fixes, the hosts have different
+	// headers, so the remaining diagnostics are for the unused host and the empty collection, which have no fixes.
+	diags, err = manifest.LintManifest(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []lintResult{
+		{manifest.LintRuleDuplicateHost, "/hosts/my-api"},
+		{manifest.LintRuleUnusedHost, "/hosts/my-api"},
+		{manifest.LintRuleEmptySearchMethods, "/collections/empty"},
+	}
+	if actual := lintResults(diags); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+}
+
+func TestLintManifest_Suppressions(t *testing.T) {
+	content := []byte(`{
+  // lint:ignore empty-search-methods
+  "models": {
+    "model-1": {
+      "sourceModel": "source-model-1",
+      "host": "my-host",
+      "dedicated": true // lint:ignore dedicated-non-hypermode
+    }
+  },
+  "hosts": {
+    // lint:ignore unused-host, plaintext-secret -- used by functions
+    "other-host": {
+      "baseUrl": "https://api.example.com/",
+      "headers": {
+        "X-API-Key": "abc123"
+      }
+    },
+    "my-host": { // lint:ignore plaintext-secret
+      "baseUrl": "https://models.example.com/",
+      "headers": {
+        "X-API-Key": "abc123"
+      }
+    }
+  },
+  "collections": {
+    "empty": {}
+  }
+}`)
+
+	diags, err := manifest.LintManifest(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []lintResult{{manifest.LintRuleEmptySearchMethods, "/collections/empty"}}
+	if actual := lintResults(diags); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+}
+
+func TestLintManifest_ValidManifest(t *testing.T) {
+	diags, err := manifest.LintManifest(validManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diags {
+		if d.Severity == manifest.SeverityError {
+			t.Errorf("Expected no lint errors, but got: %v", d)
+		}
+	}
+}
+
+func TestLintManifest_SecretsMatchValidation(t *testing.T) {
+	content := []byte(`{
+  "hosts": {
+    "my-api": {
+      "baseUrl": "https://api.example.com/",
+      "headers": {"X-Api-Key": "hunter2", "X-Request-Source": "hypermode"}
+    },
+    "my-service": {
+      "type": "grpc",
+      "grpcTarget": "models.example.com:443",
+      "metadata": {"authorization": "Bearer abc123"}
+    }
+  }
+}`)
+
+	all, err := manifest.LintManifest(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var diags []manifest.Diagnostic
+	for _, d := range all {
+		if d.Rule == manifest.LintRulePlaintextSecret {
+			diags = append(diags, d)
+		}
+	}
+	expected := []lintResult{
+		{manifest.LintRulePlaintextSecret, "/hosts/my-api/headers/X-Api-Key"},
+		{manifest.LintRulePlaintextSecret, "/hosts/my-service/metadata/authorization"},
+	}
+	if actual := lintResults(diags); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+
+	validation, err := manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(validation) != len(diags) {
+		t.Fatalf("Expected validation to find the same secrets, but got: %v", validation)
+	}
+	for i, d := range validation {
+		if d.Path != diags[i].Path {
+			t.Errorf("Expected a validation finding at %s, but got: %v", diags[i].Path, d)
+		}
+	}
+}
+
+func TestApplyFixes_PreservesFormatting(t *testing.T) {
+	content := []byte(`{
+  "models": {
+    "my-model": {"sourceModel": "source-model", "host": "my-host"}
+  },
+  "hosts": {
+    // The host for my-model.
+    "my-host": {
+      "endpoint": "https://models.example.com/path/to/my-model"
+    }
+  }
+}`)
+
+	expected := `{
+  "models": {
+    "my-model": {"sourceModel": "source-model", "host": "my-host", "path": "my-model"}
+  },
+  "hosts": {
+    // The host for my-model.
+    "my-host": {
+      "baseUrl": "https://models.example.com/path/to/"
+    }
+  }
+}`
+
+	diags, err := manifest.LintManifest(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := manifest.ApplyFixes(content, diags...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != expected {
+		t.Errorf("Expected fixed manifest:\n%s\nbut got:\n%s", expected, actual)
+	}
+}
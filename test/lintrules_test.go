@@ -0,0 +1,203 @@
+package manifest_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestLintRules(t *testing.T) {
+	rules, err := manifest.ReadLintRules([]byte(`{
+		// Custom lint rules for the test manifest.
+		"rules": [
+			{
+				"id": "dedicated-hugging-face",
+				"scope": "model",
+				"expression": "!has(model.dedicated) || model.provider == 'hugging-face'",
+				"severity": "error",
+				"message": "dedicated models must use hugging-face",
+			},
+			{
+				"id": "postgres-sslmode",
+				"scope": "host",
+				"expression": "host.type != 'postgresql' || host.connString.contains('sslmode=require')",
+				"severity": "warning",
+				"message": "postgresql hosts must use sslmode=require",
+			},
+			{
+				"id": "max-models",
+				"expression": "size(manifest.models) <= 10",
+				"message": "too many models",
+			},
+		],
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	expected := []manifest.Diagnostic{
+		{
+			Severity: manifest.SeverityWarning,
+			Path:     "/hosts/neon",
+			Message:  "postgresql hosts must use sslmode=require",
+			Rule:     "postgres-sslmode",
+		},
+	}
+	if actual := rules.Lint(&m); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+
+	m.Models["model-5"] = manifest.ModelInfo{
+		Name:        "model-5",
+		SourceModel: "source-model-5",
+		Host:        "hypermode",
+		Dedicated:   true,
+	}
+	actual := rules.Lint(&m)
+	if len(actual) != 2 || actual[0].Path != "/models/model-5" || actual[0].Severity != manifest.SeverityError {
+		t.Errorf("Expected an error for model-5, but got: %v", actual)
+	}
+}
+
+func TestLintRules_Invalid(t *testing.T) {
+	tests := map[string]manifest.LintRule{
+		"missing id":     {Expression: "true"},
+		"syntax error":   {ID: "bad", Expression: "manifest.models ==="},
+		"unknown scope":  {ID: "bad", Scope: "function", Expression: "true"},
+		"wrong variable": {ID: "bad", Scope: "model", Expression: "has(host.type)"},
+		"not a bool":     {ID: "bad", Expression: "size(manifest.models)"},
+	}
+
+	for name, rule := range tests {
+		if _, err := manifest.CompileLintRules(rule); err == nil {
+			t.Errorf("Expected an error compiling a lint rule with a %s", name)
+		}
+	}
+
+	if _, err := manifest.ReadLintRules([]byte(`{"rules": [{"id": "x", "expression": "true", "severity": "fatal"}]}`)); err == nil {
+		t.Error("Expected an error reading a lint rule with an unknown severity")
+	}
+}
+
+func TestLintRules_EvaluationError(t *testing.T) {
+	rules, err := manifest.CompileLintRules(manifest.LintRule{
+		ID:         "path-required",
+		Scope:      manifest.LintScopeModel,
+		Expression: "model.path != ''",
+		Message:    "models must have a path",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := manifest.HypermodeManifest{
+		Models: map[string]manifest.ModelInfo{
+			"my-model": {Name: "my-model", SourceModel: "source-model", Host: "my-host"},
+		},
+	}
+
+	// The path is not set, so it is not in the data, and reading it without has() fails to evaluate.
+	diags := rules.Lint(&m)
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityError || diags[0].Rule != "path-required" {
+		t.Errorf("Expected an evaluation error, but got: %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, "has()") {
+		t.Errorf("Expected the message to mention has(), but got: %s", diags[0].Message)
+	}
+}
+
+func TestLintRules_DefaultSeverity(t *testing.T) {
+	rules, err := manifest.ReadLintRules([]byte(`{"rules": [{"id": "no-models", "expression": "size(manifest.models) == 0", "message": "no models allowed"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	diags := rules.Lint(&m)
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityWarning {
+		t.Errorf("Expected a single warning, but got: %v", diags)
+	}
+
+	// Rules compiled directly have the severity they are given, and the zero value is an error.
+	compiled, err := manifest.CompileLintRules(manifest.LintRule{ID: "no-models", Expression: "size(manifest.models) == 0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diags = compiled.Lint(&m)
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityError {
+		t.Errorf("Expected a single error, but got: %v", diags)
+	}
+}
+
+func TestSeverity_Order(t *testing.T) {
+	if !(manifest.SeverityError < manifest.SeverityWarning && manifest.SeverityWarning < manifest.SeverityInfo) {
+		t.Errorf("Expected severities to be ordered from the most severe to the least severe")
+	}
+}
+
+func TestLintRules_FileFieldNames(t *testing.T) {
+	rules, err := manifest.CompileLintRules(manifest.LintRule{
+		ID:         "https-only",
+		Scope:      manifest.LintScopeHost,
+		Expression: "!has(host.baseUrl) || host.baseUrl.startsWith('https://')",
+		Severity:   manifest.SeverityWarning,
+		Message:    "hosts must use https",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := manifest.HypermodeManifest{
+		Hosts: map[string]manifest.HostInfo{
+			"secure-api":   manifest.HTTPHostInfo{Name: "secure-api", BaseURL: "https://api.example.com/"},
+			"insecure-api": manifest.HTTPHostInfo{Name: "insecure-api", BaseURL: "http://api.example.com/"},
+			"my-openai":    manifest.OpenAIHostInfo{Name: "my-openai", BaseURL: "http://localhost:11434/v1/"},
+		},
+	}
+
+	expected := []manifest.Diagnostic{
+		{Severity: manifest.SeverityWarning, Path: "/hosts/insecure-api", Message: "hosts must use https", Rule: "https-only"},
+		{Severity: manifest.SeverityWarning, Path: "/hosts/my-openai", Message: "hosts must use https", Rule: "https-only"},
+	}
+	if actual := rules.Lint(&m); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics: %v, but got: %v", expected, actual)
+	}
+}
+
+func TestLintRules_UnsetFields(t *testing.T) {
+	rules, err := manifest.CompileLintRules(manifest.LintRule{
+		ID:         "path-prefix",
+		Scope:      manifest.LintScopeModel,
+		Expression: "!has(model.path) || model.path.startsWith('v1/')",
+		Message:    "model paths must start with v1/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty string is not set, the same as a missing field.
+	m := manifest.HypermodeManifest{
+		Models: map[string]manifest.ModelInfo{
+			"empty-path": {Name: "empty-path", SourceModel: "source-model", Host: "my-host", Path: ""},
+			"no-path":    {Name: "no-path", SourceModel: "source-model", Host: "my-host"},
+			"v1-path":    {Name: "v1-path", SourceModel: "source-model", Host: "my-host", Path: "v1/model"},
+			"v2-path":    {Name: "v2-path", SourceModel: "source-model", Host: "my-host", Path: "v2/model"},
+		},
+	}
+
+	diags := rules.Lint(&m)
+	if len(diags) != 1 || diags[0].Path != "/models/v2-path" {
+		t.Errorf("Expected a single warning for v2-path, but got: %v", diags)
+	}
+}
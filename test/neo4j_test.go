@@ -0,0 +1,66 @@
+package manifest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestValidateManifest_Neo4jURI(t *testing.T) {
+	tests := []struct {
+		uri   string
+		valid bool
+	}{
+		{"neo4j://localhost", true},
+		{"neo4j+s://xxxxxxxx.databases.neo4j.io", true},
+		{"neo4j+ssc://graph.example.com:7687", true},
+		{"bolt://localhost:7687", true},
+		{"bolt+s://graph.example.com/", true},
+		{"http://graph.example.com", false},
+		{"neo4j+x://graph.example.com", false},
+		{"graph.example.com:7687", false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-graph": {"type": "neo4j", "uri": "` + tt.uri + `", "password": "{{NEO4J_PASSWORD}}"}}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.uri, tt.valid, err)
+		}
+	}
+}
+
+func TestNeo4jHostInfo_Validate(t *testing.T) {
+	host := manifest.Neo4jHostInfo{
+		Name:     "my-graph",
+		URI:      "neo4j+s://graph.example.com",
+		Username: "neo4j",
+		Password: "hunter2",
+	}
+
+	if actual := fmt.Sprint(host); actual != `{"name":"my-graph","password":"****","type":"neo4j","uri":"neo4j+s://graph.example.com","username":"neo4j"}` {
+		t.Errorf("Expected the password to be masked, but got: %s", actual)
+	}
+
+	diags := host.Validate()
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityWarning || diags[0].Path != "/hosts/my-graph/password" {
+		t.Errorf("Expected a single warning for the hard-coded password, but got: %v", diags)
+	}
+
+	host.URI = "http://graph.example.com"
+	host.Password = "{{NEO4J_PASSWORD}}"
+	diags = host.Validate()
+	if len(diags) != 1 || diags[0].Path != "/hosts/my-graph/uri" {
+		t.Errorf("Expected a single error for the uri scheme, but got: %v", diags)
+	}
+
+	m := manifest.HypermodeManifest{Hosts: map[string]manifest.HostInfo{"my-graph": manifest.Neo4jHostInfo{
+		Name: "my-graph",
+		URI:  "bolt://graph.example.com",
+	}}}
+	policy := manifest.Policy{AllowedDomains: []string{"graph.example.com"}, AllowedPorts: []int{443}}
+	violations := manifest.CheckPolicy(m, policy)
+	if len(violations) != 1 || violations[0].Message != "port 7687 is not allowed by the policy" {
+		t.Errorf("Expected a single violation for the default port, but got: %v", violations)
+	}
+}
@@ -0,0 +1,393 @@
+package manifest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func newTestTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		clientID, clientSecret := r.Form.Get("client_id"), r.Form.Get("client_secret")
+		if id, secret, ok := r.BasicAuth(); ok {
+			clientID, _ = url.QueryUnescape(id)
+			clientSecret, _ = url.QueryUnescape(secret)
+		}
+
+		if r.Form.Get("grant_type") != "client_credentials" ||
+			clientID != "my-client" ||
+			clientSecret != "my-secret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+
+		if scope := r.Form.Get("scope"); scope != "read write" {
+			t.Errorf("Expected scope: %q, but got: %q", "read write", scope)
+		}
+		if audience := r.Form.Get("audience"); audience != "https://api.example.com/" {
+			t.Errorf("Expected audience: %q, but got: %q", "https://api.example.com/", audience)
+		}
+
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+
+	return server, &issued
+}
+
+func newTestAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+}
+
+func TestAuthTransport_OAuth2ClientCredentials(t *testing.T) {
+	tokenServer, issued := newTestTokenServer(t, 3600)
+	defer tokenServer.Close()
+
+	apiServer := newTestAPIServer(t)
+	defer apiServer.Close()
+
+	host := manifest.HTTPHostInfo{
+		Name:    "oauth-api",
+		BaseURL: apiServer.URL + "/",
+		Auth: &manifest.AuthInfo{
+			Type:         manifest.AuthTypeOAuth2ClientCredentials,
+			TokenURL:     tokenServer.URL,
+			ClientID:     "my-client",
+			ClientSecret: "my-secret",
+			Scopes:       []string{"read", "write"},
+			Audience:     "https://api.example.com/",
+		},
+	}
+
+	transport, err := host.Transport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		if auth := getBody(t, client, apiServer.URL); auth != "Bearer token-1" {
+			t.Errorf("Expected authorization: %q, but got: %q", "Bearer token-1", auth)
+		}
+	}
+
+	// The token should be cached, and only requested once.
+	if n := issued.Load(); n != 1 {
+		t.Errorf("Expected 1 token request, but got: %d", n)
+	}
+}
+
+func TestAuthTransport_OAuth2RefreshesExpiredToken(t *testing.T) {
+	// Tokens with a short lifetime are cached for most of it, and refreshed before they expire.
+	tokenServer, issued := newTestTokenServer(t, 1)
+	defer tokenServer.Close()
+
+	apiServer := newTestAPIServer(t)
+	defer apiServer.Close()
+
+	auth := &manifest.AuthInfo{
+		Type:         manifest.AuthTypeOAuth2ClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+		Scopes:       []string{"read", "write"},
+		Audience:     "https://api.example.com/",
+	}
+
+	transport, err := auth.Transport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+
+	getBody(t, client, apiServer.URL)
+	if auth := getBody(t, client, apiServer.URL); auth != "Bearer token-1" {
+		t.Errorf("Expected authorization: %q, but got: %q", "Bearer token-1", auth)
+	}
+
+	time.Sleep(800 * time.Millisecond)
+	if auth := getBody(t, client, apiServer.URL); auth != "Bearer token-2" {
+		t.Errorf("Expected authorization: %q, but got: %q", "Bearer token-2", auth)
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("Expected 2 token requests, but got: %d", n)
+	}
+}
+
+func TestAuthTransport_OAuth2CancelledCaller(t *testing.T) {
+	release := make(chan struct{})
+	var issued atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": fmt.Sprintf("token-%d", n), "expires_in": 3600})
+	}))
+	defer tokenServer.Close()
+
+	apiServer := newTestAPIServer(t)
+	defer apiServer.Close()
+
+	auth := &manifest.AuthInfo{
+		Type:         manifest.AuthTypeOAuth2ClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+	}
+	transport, err := auth.Transport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+
+	// The first caller starts the token request, and then gives up waiting for it.
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiServer.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled := make(chan error, 1)
+	go func() {
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancelled <- err
+	}()
+
+	// A second caller waits for the same token request.
+	result := make(chan string, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		result <- getBody(t, client, apiServer.URL)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled caller to fail with: %v, but got: %v", context.Canceled, err)
+	}
+
+	close(release)
+	if auth := <-result; auth != "Bearer token-1" {
+		t.Errorf("Expected authorization: %q, but got: %q", "Bearer token-1", auth)
+	}
+	if n := issued.Load(); n != 1 {
+		t.Errorf("Expected 1 token request, but got: %d", n)
+	}
+}
+
+func TestAuthTransport_OAuth2InvalidClient(t *testing.T) {
+	tokenServer, _ := newTestTokenServer(t, 3600)
+	defer tokenServer.Close()
+
+	auth := &manifest.AuthInfo{
+		Type:         manifest.AuthTypeOAuth2ClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "wrong-secret",
+	}
+
+	transport, err := auth.Transport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("http://127.0.0.1:0/")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected an error when the token request fails")
+	}
+
+	// Only the standard error code is reported, not the response body.
+	if !strings.HasSuffix(err.Error(), "oauth2 token request failed with status 401: invalid_client") {
+		t.Errorf("Expected the error code in the error, but got: %v", err)
+	}
+}
+
+func TestAuthTransport_OAuth2ErrorOmitsBody(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials: client_secret=my-secret", http.StatusBadRequest)
+	}))
+	defer tokenServer.Close()
+
+	auth := &manifest.AuthInfo{
+		Type:         manifest.AuthTypeOAuth2ClientCredentials,
+		TokenURL:     tokenServer.URL,
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+	}
+	transport, err := auth.Transport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("http://127.0.0.1:0/")
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected an error when the token request fails")
+	}
+	if strings.Contains(err.Error(), "my-secret") {
+		t.Errorf("Expected the error not to include the response body, but got: %v", err)
+	}
+}
+
+func TestAuthTransport_Unresolved(t *testing.T) {
+	auth := &manifest.AuthInfo{
+		Type:         manifest.AuthTypeOAuth2ClientCredentials,
+		TokenURL:     "https://auth.example.com/oauth/token",
+		ClientID:     "my-client",
+		ClientSecret: "{{CLIENT_SECRET}}",
+	}
+
+	if _, err := auth.Transport(nil); err == nil {
+		t.Error("Expected an error building a transport from unresolved settings")
+	}
+}
+
+func TestAuthInfo_OAuth2Variables(t *testing.T) {
+	host := manifest.HTTPHostInfo{
+		Name:    "my-api",
+		BaseURL: "https://api.example.com/",
+		Auth: &manifest.AuthInfo{
+			Type:         manifest.AuthTypeOAuth2ClientCredentials,
+			TokenURL:     "https://login.microsoftonline.com/{{TENANT_ID}}/oauth2/v2.0/token",
+			ClientID:     "{{CLIENT_ID}}",
+			ClientSecret: "{{CLIENT_SECRET}}",
+			Scopes:       []string{"api://{{APP_ID}}/.default"},
+			Audience:     "{{AUDIENCE}}",
+		},
+	}
+
+	expected := []string{"TENANT_ID", "CLIENT_ID", "CLIENT_SECRET", "APP_ID", "AUDIENCE"}
+	if actual := host.GetVariables(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected variables: %v, but got: %v", expected, actual)
+	}
+
+	// Only the token URL is unresolved.
+	auth := *host.Auth
+	auth.ClientID = "my-client"
+	auth.ClientSecret = "my-secret"
+	auth.Scopes = []string{"api://my-app/.default"}
+	auth.Audience = "my-audience"
+	if _, err := auth.Transport(nil); err == nil {
+		t.Error("Expected an error building a transport with an unresolved token URL")
+	}
+}
+
+func getBody(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestValidateManifest_OAuth2RequiresSecretTemplate(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"oauth-api": {
+				"baseUrl": "https://api.example.com/",
+				"auth": {
+					"type": "oauth2-client-credentials",
+					"tokenURL": "https://auth.example.com/oauth/token",
+					"clientId": "my-client",
+					"clientSecret": "my-secret"
+				}
+			}
+		}
+	}`)
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with a literal client secret")
+	}
+}
+
+func TestAuthTransport_OAuth2ClientAuthMethod(t *testing.T) {
+	tests := []struct {
+		method    string
+		basicAuth bool
+	}{
+		{"", true},
+		{manifest.OAuth2ClientSecretBasic, true},
+		{manifest.OAuth2ClientSecretPost, false},
+	}
+
+	for _, tt := range tests {
+		var basicAuth, bodyAuth bool
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				// The authenticated request itself.
+				return
+			}
+			_, _, basicAuth = r.BasicAuth()
+			bodyAuth = r.PostFormValue("client_secret") != ""
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+		}))
+
+		auth := &manifest.AuthInfo{
+			Type:             manifest.AuthTypeOAuth2ClientCredentials,
+			TokenURL:         tokenServer.URL,
+			ClientID:         "my client",
+			ClientSecret:     "my:secret",
+			ClientAuthMethod: tt.method,
+		}
+		transport, err := auth.Transport(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, _ := http.NewRequest(http.MethodGet, tokenServer.URL, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("Expected no error for client auth method %q, but got: %v", tt.method, err)
+		}
+		resp.Body.Close()
+		tokenServer.Close()
+
+		if basicAuth != tt.basicAuth || bodyAuth == tt.basicAuth {
+			t.Errorf("Expected Basic authentication %v for client auth method %q, but got %v, and credentials in the body %v",
+				tt.basicAuth, tt.method, basicAuth, bodyAuth)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package manifest_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestValidateManifest_OpenAI(t *testing.T) {
+	tests := []struct {
+		name  string
+		host  string
+		valid bool
+	}{
+		{"openai", `{"type": "openai", "baseUrl": "https://api.openai.com/v1/", "apiKey": "{{OPENAI_API_KEY}}", "organization": "org-example", "project": "proj-example"}`, true},
+		{"local", `{"type": "openai", "baseUrl": "http://localhost:11434/v1/"}`, true},
+		{"azure", `{"type": "openai", "baseUrl": "https://example.openai.azure.com/openai/deployments/gpt-4o/", "apiKey": "{{AZURE_OPENAI_KEY}}", "apiVersion": "2024-06-01"}`, true},
+		{"azure preview", `{"type": "openai", "baseUrl": "https://example.openai.azure.com/openai/", "apiKey": "{{AZURE_OPENAI_KEY}}", "apiVersion": "2024-08-01-preview"}`, true},
+		{"missing base url", `{"type": "openai", "apiKey": "{{OPENAI_API_KEY}}"}`, false},
+		{"missing trailing slash", `{"type": "openai", "baseUrl": "https://api.openai.com/v1"}`, false},
+		{"literal api key", `{"type": "openai", "baseUrl": "https://api.openai.com/v1/", "apiKey": "sk-abc123"}`, false},
+		{"azure without api key", `{"type": "openai", "baseUrl": "https://example.openai.azure.com/openai/", "apiVersion": "2024-06-01"}`, false},
+		{"invalid api version", `{"type": "openai", "baseUrl": "https://example.openai.azure.com/openai/", "apiKey": "{{AZURE_OPENAI_KEY}}", "apiVersion": "latest"}`, false},
+		{"unknown field", `{"type": "openai", "baseUrl": "https://api.openai.com/v1/", "headers": {"X-API-Key": "{{API_KEY}}"}}`, false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-openai": ` + tt.host + `}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidateManifest_OpenAISourceModel(t *testing.T) {
+	content := []byte(`{
+		"models": {
+			"my-model": {
+				"host": "my-openai"
+			}
+		},
+		"hosts": {
+			"my-openai": {
+				"type": "openai",
+				"baseUrl": "https://api.openai.com/v1/",
+				"apiKey": "{{OPENAI_API_KEY}}"
+			}
+		}
+	}`)
+
+	diags, err := manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{})
+	if err != nil {
+		t.Fatalf("Error validating manifest: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityError || diags[0].Path != "/models/my-model/sourceModel" {
+		t.Errorf("Expected a single error for the missing sourceModel, but got: %v", diags)
+	}
+}
+
+func TestOpenAIHostInfo_HTTPHost(t *testing.T) {
+	host := manifest.OpenAIHostInfo{
+		Name:         "my-openai",
+		BaseURL:      "https://api.openai.com/v1/",
+		APIKey:       "{{OPENAI_API_KEY}}",
+		Organization: "org-example",
+		Project:      "proj-example",
+	}
+
+	expected := manifest.HTTPHostInfo{
+		Name:    "my-openai",
+		Type:    manifest.HostTypeHTTP,
+		BaseURL: "https://api.openai.com/v1/",
+		Headers: map[string]string{
+			"Authorization":       "Bearer {{OPENAI_API_KEY}}",
+			"OpenAI-Organization": "org-example",
+			"OpenAI-Project":      "proj-example",
+		},
+	}
+	if actual := host.HTTPHost(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected host: %+v, but got: %+v", expected, actual)
+	}
+
+	azure := manifest.OpenAIHostInfo{
+		Name:       "my-azure",
+		BaseURL:    "https://example.openai.azure.com/openai/deployments/gpt-4o/",
+		APIKey:     "{{AZURE_OPENAI_KEY}}",
+		APIVersion: "2024-06-01",
+	}
+
+	expected = manifest.HTTPHostInfo{
+		Name:            "my-azure",
+		Type:            manifest.HostTypeHTTP,
+		BaseURL:         "https://example.openai.azure.com/openai/deployments/gpt-4o/",
+		Headers:         map[string]string{"api-key": "{{AZURE_OPENAI_KEY}}"},
+		QueryParameters: map[string]string{"api-version": "2024-06-01"},
+	}
+	if actual := azure.HTTPHost(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected host: %+v, but got: %+v", expected, actual)
+	}
+
+	local := manifest.OpenAIHostInfo{Name: "my-ollama", BaseURL: "http://localhost:11434/v1/"}
+	if actual := local.HTTPHost(); actual.Headers != nil || actual.QueryParameters != nil {
+		t.Errorf("Expected no headers or query parameters, but got: %+v", actual)
+	}
+}
+
+func TestOpenAIHostInfo_String(t *testing.T) {
+	host := manifest.OpenAIHostInfo{
+		Name:    "my-openai",
+		BaseURL: "https://api.openai.com/v1/",
+		APIKey:  "sk-abc123",
+	}
+
+	expected := `{"apiKey":"****","baseUrl":"https://api.openai.com/v1/","name":"my-openai","type":"openai"}`
+	if actual := host.String(); actual != expected {
+		t.Errorf("Expected string: %s, but got: %s", expected, actual)
+	}
+}
+
+func TestGetHostSecretVariablesFromManifest(t *testing.T) {
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	vars := m.GetHostSecretVariables()
+	expected := []string{"OPENAI_API_KEY"}
+	if actual := vars["my-openai"]; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected secret vars: %v, but got: %v", expected, actual)
+	}
+}
+
+func TestHypermodeManifest_ModelURL_OpenAI(t *testing.T) {
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	expected := "https://api.openai.com/v1/chat/completions"
+	if actual, err := m.ModelURL("model-5"); err != nil {
+		t.Error(err)
+	} else if actual != expected {
+		t.Errorf("Expected url: %s, but got: %s", expected, actual)
+	}
+}
@@ -0,0 +1,134 @@
+package manifest_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+// newTestProxy returns a stand-in for a forward proxy.  Rather than forwarding requests,
+// it responds with the absolute URL that was requested through it.
+func newTestProxy(t *testing.T, username, password string) *httptest.Server {
+	t.Helper()
+
+	expectedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") != expectedAuth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if !r.URL.IsAbs() {
+			http.Error(w, "expected an absolute url", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("proxied " + r.URL.String()))
+	}))
+}
+
+func TestProxyFunc(t *testing.T) {
+	proxy := newTestProxy(t, "alice", "secret")
+	defer proxy.Close()
+
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer direct.Close()
+
+	// This is the host after the runtime has resolved the templates.
+	host := manifest.HTTPHostInfo{
+		Name:    "proxied-api",
+		BaseURL: "http://api.example.com/",
+		Proxy: &manifest.ProxyInfo{
+			URL:      proxy.URL,
+			Username: "alice",
+			Password: "secret",
+			NoProxy:  []string{"127.0.0.1"},
+		},
+	}
+
+	proxyFunc, err := host.ProxyFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: &http.Transport{Proxy: proxyFunc}}
+
+	if body := getBody(t, client, "http://api.example.com/v1/items"); body != "proxied http://api.example.com/v1/items" {
+		t.Errorf("Expected the request to be proxied, but got: %s", body)
+	}
+
+	if body := getBody(t, client, direct.URL); body != "direct" {
+		t.Errorf("Expected the request to bypass the proxy, but got: %s", body)
+	}
+}
+
+func TestProxyFunc_NoProxy(t *testing.T) {
+	p := &manifest.ProxyInfo{
+		URL: "socks5://proxy.example.com:1080",
+		NoProxy: []string{
+			".internal.example.com",
+			"example.org",
+			"10.0.0.0/8",
+			"192.168.1.1",
+			"localhost:8080",
+		},
+	}
+
+	proxyFunc, err := p.ProxyFunc()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"https://api.internal.example.com/": false,
+		"https://internal.example.com/":     false,
+		"https://example.org/":              false,
+		"https://api.example.org/":          false,
+		"https://notexample.org/":           true,
+		"http://10.1.2.3/":                  false,
+		"http://11.1.2.3/":                  true,
+		"http://192.168.1.1:8443/":          false,
+		"http://localhost:8080/":            false,
+		"http://localhost:9090/":            true,
+		"https://api.example.com/":          true,
+	}
+
+	for target, proxied := range tests {
+		u, _ := url.Parse(target)
+		proxyURL, err := proxyFunc(&http.Request{URL: u})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (proxyURL != nil) != proxied {
+			t.Errorf("Expected %s to be proxied: %v, but got proxy: %v", target, proxied, proxyURL)
+		}
+	}
+}
+
+func TestProxyFunc_Unresolved(t *testing.T) {
+	p := &manifest.ProxyInfo{
+		URL:      "http://proxy.example.com:3128",
+		Username: "{{PROXY_USERNAME}}",
+		Password: "{{PROXY_PASSWORD}}",
+	}
+
+	if _, err := p.ProxyFunc(); err == nil {
+		t.Error("Expected an error building a proxy function from unresolved settings")
+	}
+}
+
+func TestHttpHostInfo_HashWithProxy(t *testing.T) {
+	host := manifest.HTTPHostInfo{
+		Name:    "my-host",
+		BaseURL: "https://example.com/api/",
+	}
+
+	expectedHash := host.Hash()
+	host.Proxy = &manifest.ProxyInfo{URL: "http://proxy.example.com:3128"}
+	if actualHash := host.Hash(); actualHash == expectedHash {
+		t.Error("Expected hash to change when a proxy is set")
+	}
+}
@@ -0,0 +1,112 @@
+package manifest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestValidateManifest_SQLitePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"dev.db", true},
+		{"data/dev.db", true},
+		{"./data/dev.db", true},
+		{"data/../dev.db", true},
+		{":memory:", true},
+		{"../dev.db", false},
+		{"data/../../dev.db", false},
+		{"..", false},
+		{"/var/lib/dev.db", false},
+		{`C:\\data\\dev.db`, false},
+		{`..\\dev.db`, false},
+		{"file:dev.db?mode=ro", false},
+		{"{{DB_PATH}}", false},
+	}
+
+	for _, tt := range tests {
+		content := []byte(`{"hosts": {"my-database": {"type": "sqlite", "path": "` + tt.path + `"}}}`)
+		if err := manifest.ValidateManifest(content); (err == nil) != tt.valid {
+			t.Errorf("Expected %s to be valid: %v, but got: %v", tt.path, tt.valid, err)
+		}
+	}
+}
+
+func TestReadManifest_LocalFile(t *testing.T) {
+	content := []byte(`{"hosts": {"my-database": {"type": "local-file", "path": "data/dev.db", "wal": true}}}`)
+	if err := manifest.ValidateManifest(content); err != nil {
+		t.Fatalf("Error validating manifest: %v", err)
+	}
+
+	m, err := manifest.ReadManifest(content)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+	host, ok := m.Hosts["my-database"].(manifest.SQLiteHostInfo)
+	if !ok {
+		t.Fatalf("Expected a sqlite host, but got: %T", m.Hosts["my-database"])
+	}
+	if host.HostType() != manifest.HostTypeSQLite || host.Path != "data/dev.db" || !host.WAL {
+		t.Errorf("Expected a sqlite host for data/dev.db with wal, but got: %+v", host)
+	}
+
+	// The alias hashes the same as the sqlite type.
+	sqlite := manifest.SQLiteHostInfo{Name: "my-database", Type: manifest.HostTypeSQLite, Path: "data/dev.db", WAL: true}
+	if host.Hash() != sqlite.Hash() || !host.Equal(sqlite) {
+		t.Errorf("Expected the local-file host to equal the sqlite host, but got: %s and %s", host.Hash(), sqlite.Hash())
+	}
+
+	if err := manifest.ValidateManifest([]byte(`{"hosts": {"my-database": {"type": "local-file", "path": "../dev.db"}}}`)); err == nil {
+		t.Error("Expected an error validating a local-file path that escapes the project")
+	}
+}
+
+func TestSQLiteHostInfo_Validate(t *testing.T) {
+	host := manifest.SQLiteHostInfo{Name: "my-database", Path: "dev.db", ReadOnly: true, WAL: true}
+	diags := host.Validate()
+	if len(diags) != 1 || diags[0].Path != "/hosts/my-database/wal" {
+		t.Errorf("Expected a single error for wal with readOnly, but got: %v", diags)
+	}
+
+	content := []byte(`{"hosts": {"my-database": {"type": "sqlite", "path": "dev.db"}}}`)
+	diags, err := manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{Production: true})
+	if err != nil {
+		t.Fatalf("Error validating manifest: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityError || diags[0].Path != "/hosts/my-database/type" {
+		t.Errorf("Expected a single error for a sqlite host in production, but got: %v", diags)
+	}
+}
+
+func TestSQLiteHostInfo_ResolvePath(t *testing.T) {
+	projectDir := t.TempDir()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"data/dev.db", filepath.Join(projectDir, "data", "dev.db")},
+		{"./data/../dev.db", filepath.Join(projectDir, "dev.db")},
+		{":memory:", ":memory:"},
+		{"../dev.db", ""},
+	}
+
+	for _, tt := range tests {
+		host := manifest.SQLiteHostInfo{Name: "my-database", Path: tt.path}
+		actual, err := host.ResolvePath(projectDir)
+		if tt.expected == "" {
+			if err == nil {
+				t.Errorf("Expected an error for path %s, but got: %s", tt.path, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error resolving path %s: %v", tt.path, err)
+		} else if actual != tt.expected {
+			t.Errorf("Expected resolved path: %s, but got: %s", tt.expected, actual)
+		}
+	}
+}
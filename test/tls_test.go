@@ -0,0 +1,224 @@
+package manifest_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestValidateManifest_InsecureSkipVerify(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"dev-api": {
+				"baseUrl": "https://localhost:8443/",
+				"tls": {
+					"insecureSkipVerify": true
+				}
+			}
+		}
+	}`)
+
+	// Allowed for development, with a warning.
+	diags, err := manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityWarning || diags[0].Path != "/hosts/dev-api/tls/insecureSkipVerify" {
+		t.Errorf("Expected a single insecureSkipVerify warning, but got: %v", diags)
+	}
+	if err := manifest.ValidateManifest(content); err != nil {
+		t.Errorf("Expected no error without the production policy, but got: %v", err)
+	}
+
+	// Forbidden by the production policy.
+	diags, err = manifest.ValidateManifestWithOptions(content, manifest.ValidationOptions{Production: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diags) != 1 || diags[0].Severity != manifest.SeverityError {
+		t.Errorf("Expected a single insecureSkipVerify error, but got: %v", diags)
+	}
+}
+
+func TestValidateManifest_TLSRequiresTemplates(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"internal-api": {
+				"baseUrl": "https://internal.example.com/",
+				"tls": {
+					"clientCert": "-----BEGIN CERTIFICATE-----",
+					"clientKey": "{{CLIENT_KEY}}"
+				}
+			}
+		}
+	}`)
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with a literal client certificate")
+	}
+}
+
+func TestValidateManifest_TLSClientCertRequiresKey(t *testing.T) {
+	content := []byte(`{
+		"hosts": {
+			"internal-api": {
+				"baseUrl": "https://internal.example.com/",
+				"tls": {
+					"clientCert": "{{CLIENT_CERT}}"
+				}
+			}
+		}
+	}`)
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with a client certificate but no key")
+	}
+}
+
+func TestTLSConfig_Unresolved(t *testing.T) {
+	host := manifest.HTTPHostInfo{
+		Name:    "internal-api",
+		BaseURL: "https://internal.example.com/",
+		TLS: &manifest.TLSInfo{
+			CACert: "{{CA_CERT}}",
+		},
+	}
+
+	if _, err := host.TLSConfig(); err == nil {
+		t.Error("Expected an error building a TLS configuration from unresolved settings")
+	}
+}
+
+func TestTLSConfig_MutualTLS(t *testing.T) {
+	clientCA, clientCAKey := newTestCA(t)
+	clientCertPEM, clientKeyPEM := newTestClientCert(t, clientCA, clientCAKey)
+
+	clientPool := x509.NewCertPool()
+	clientPool.AddCert(clientCA)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientPool,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	serverCAPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	// This is the host after the runtime has resolved the templates.
+	host := manifest.HTTPHostInfo{
+		Name:    "internal-api",
+		BaseURL: server.URL + "/",
+		TLS: &manifest.TLSInfo{
+			CACert:     string(serverCAPEM),
+			ClientCert: string(clientCertPEM),
+			ClientKey:  string(clientKeyPEM),
+			ServerName: "example.com",
+		},
+	}
+
+	cfg, err := host.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the request to succeed with mutual TLS, but got: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status: %d, but got: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Without the client certificate, the server should reject the connection.
+	host.TLS.ClientCert = ""
+	host.TLS.ClientKey = ""
+	cfg, err = host.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: cfg}}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Error("Expected the request to fail without a client certificate")
+	}
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
+func newTestClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
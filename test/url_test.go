@@ -0,0 +1,268 @@
+package manifest_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/hypermodeinc/manifest"
+)
+
+func TestHttpHostInfo_BuildURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     manifest.HTTPHostInfo
+		path     string
+		query    url.Values
+		expected string
+	}{
+		{
+			name:     "endpoint",
+			host:     manifest.HTTPHostInfo{Endpoint: "https://api.example.com/graphql"},
+			expected: "https://api.example.com/graphql",
+		},
+		{
+			name:     "base url without path",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			expected: "https://api.example.com/v1/",
+		},
+		{
+			name:     "base url with path",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://models.example.com/"},
+			path:     "path/to/model-2",
+			expected: "https://models.example.com/path/to/model-2",
+		},
+		{
+			name:     "path with dot segments inside the base",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "users/../items",
+			expected: "https://api.example.com/v1/items",
+		},
+		{
+			name:     "path with escaped characters",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "items/a%20b",
+			expected: "https://api.example.com/v1/items/a%20b",
+		},
+		{
+			name: "query parameters",
+			host: manifest.HTTPHostInfo{
+				BaseURL:         "https://api.example.com/v1/?format=json",
+				QueryParameters: map[string]string{"api_token": "abc 123", "page": "1"},
+			},
+			path:     "items",
+			query:    url.Values{"page": {"2"}},
+			expected: "https://api.example.com/v1/items?api_token=abc+123&format=json&page=2",
+		},
+		{
+			name: "api key auth in query",
+			host: manifest.HTTPHostInfo{
+				BaseURL: "https://api.example.com/v1/",
+				Auth:    &manifest.AuthInfo{Type: manifest.AuthTypeAPIKey, KeyName: "key", In: manifest.APIKeyInQuery, Value: "abc"},
+			},
+			path:     "items",
+			expected: "https://api.example.com/v1/items?key=abc",
+		},
+		{
+			name: "v1 host, with the same endpoint and base url",
+			host: manifest.HTTPHostInfo{
+				Endpoint: "https://models.example.com/full/path/to/model-1",
+				BaseURL:  "https://models.example.com/full/path/to/model-1",
+			},
+			expected: "https://models.example.com/full/path/to/model-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.host.BuildURL(tt.path, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("Expected url: %s, but got: %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestHttpHostInfo_BuildURLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     manifest.HTTPHostInfo
+		path     string
+		expected error
+	}{
+		{
+			name:     "no url",
+			host:     manifest.HTTPHostInfo{},
+			expected: manifest.ErrNoHostURL,
+		},
+		{
+			name:     "path with endpoint",
+			host:     manifest.HTTPHostInfo{Endpoint: "https://api.example.com/graphql"},
+			path:     "more",
+			expected: manifest.ErrPathWithEndpoint,
+		},
+		{
+			name:     "missing trailing slash",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1"},
+			path:     "items",
+			expected: manifest.ErrMissingTrailingSlash,
+		},
+		{
+			name:     "absolute path",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "/admin",
+			expected: manifest.ErrInvalidPath,
+		},
+		{
+			name:     "absolute url",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "https://evil.example.com/",
+			expected: manifest.ErrInvalidPath,
+		},
+		{
+			name:     "path traversal",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "../admin",
+			expected: manifest.ErrPathTraversal,
+		},
+		{
+			name:     "encoded path traversal",
+			host:     manifest.HTTPHostInfo{BaseURL: "https://api.example.com/v1/"},
+			path:     "items/%2e%2e/%2e%2e/admin",
+			expected: manifest.ErrPathTraversal,
+		},
+		{
+			name: "unresolved query parameters",
+			host: manifest.HTTPHostInfo{
+				BaseURL:         "https://api.example.com/v1/",
+				QueryParameters: map[string]string{"api_token": "{{API_TOKEN}}"},
+			},
+			expected: manifest.ErrUnresolvedTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.host.BuildURL(tt.path, nil)
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected error: %v, but got: %v", tt.expected, err)
+			}
+
+			var urlErr *manifest.URLError
+			if !errors.As(err, &urlErr) {
+				t.Errorf("Expected a *manifest.URLError, but got: %T", err)
+			}
+		})
+	}
+}
+
+func TestHypermodeManifest_ModelURL(t *testing.T) {
+	m, err := manifest.ReadManifest(validManifest)
+	if err != nil {
+		t.Fatalf("Error reading manifest: %v", err)
+	}
+
+	expected := "https://models.example.com/path/to/model-2"
+	if actual, err := m.ModelURL("model-2"); err != nil {
+		t.Error(err)
+	} else if actual != expected {
+		t.Errorf("Expected url: %s, but got: %s", expected, actual)
+	}
+
+	expected = "https://models.example.com/"
+	if actual, err := m.ModelURL("model-3"); err != nil {
+		t.Error(err)
+	} else if actual != expected {
+		t.Errorf("Expected url: %s, but got: %s", expected, actual)
+	}
+
+	if _, err := m.ModelURL("model-1"); !errors.Is(err, manifest.ErrHypermodeHostedModel) {
+		t.Errorf("Expected error: %v, but got: %v", manifest.ErrHypermodeHostedModel, err)
+	}
+
+	_, err = m.ModelURL("no-such-model")
+	if !errors.Is(err, manifest.ErrModelNotFound) {
+		t.Errorf("Expected error: %v, but got: %v", manifest.ErrModelNotFound, err)
+	}
+	var urlErr *manifest.URLError
+	if !errors.As(err, &urlErr) || urlErr.Model != "no-such-model" {
+		t.Errorf("Expected a *manifest.URLError for the model, but got: %#v", err)
+	} else if expected := "failed to build url for model [no-such-model]: model not found"; err.Error() != expected {
+		t.Errorf("Expected error message: %s, but got: %s", expected, err.Error())
+	}
+}
+
+func TestHypermodeManifest_ModelURL_GraphQL(t *testing.T) {
+	m := manifest.HypermodeManifest{
+		Models: map[string]manifest.ModelInfo{
+			"my-model": {Name: "my-model", Host: "my-api"},
+		},
+		Hosts: map[string]manifest.HostInfo{
+			"my-api": manifest.GraphQLHostInfo{
+				HTTPHostInfo: manifest.HTTPHostInfo{
+					Name:            "my-api",
+					Type:            manifest.HostTypeGraphQL,
+					Endpoint:        "https://api.example.com/graphql",
+					QueryParameters: map[string]string{"version": "2"},
+				},
+			},
+		},
+	}
+
+	expected := "https://api.example.com/graphql?version=2"
+	if actual, err := m.ModelURL("my-model"); err != nil {
+		t.Error(err)
+	} else if actual != expected {
+		t.Errorf("Expected url: %s, but got: %s", expected, actual)
+	}
+
+	// A path can't be applied to the endpoint of a graphql host.
+	m.Models["my-model"] = manifest.ModelInfo{Name: "my-model", Host: "my-api", Path: "v2"}
+	if _, err := m.ModelURL("my-model"); !errors.Is(err, manifest.ErrPathWithEndpoint) {
+		t.Errorf("Expected error: %v, but got: %v", manifest.ErrPathWithEndpoint, err)
+	}
+}
+
+func TestValidateManifest_ModelPathTraversal(t *testing.T) {
+	content := []byte(`{
+		"models": {
+			"my-model": {
+				"host": "my-model-host",
+				"path": "../admin"
+			}
+		},
+		"hosts": {
+			"my-model-host": {
+				"baseUrl": "https://models.example.com/"
+			}
+		}
+	}`)
+
+	if err := manifest.ValidateManifest(content); err == nil {
+		t.Error("Expected an error validating a manifest with a model path that escapes the base URL")
+	}
+}
+
+func TestValidateManifest_ModelPathWithLeadingSlash(t *testing.T) {
+	content := []byte(`{
+		"models": {
+			"my-model": {
+				"host": "my-model-host",
+				"path": "/v1/chat?format=json"
+			}
+		},
+		"hosts": {
+			"my-model-host": {
+				"baseUrl": "https://models.example.com/"
+			}
+		}
+	}`)
+
+	// Such paths are rejected when the URL is built, but still match the schema, as they did before.
+	if err := manifest.ValidateManifest(content); err != nil {
+		t.Errorf("Expected no error validating a manifest with a leading slash in a model path, but got: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+type TLSInfo struct {
+	CACert             string `json:"caCert"`
+	ClientCert         string `json:"clientCert"`
+	ClientKey          string `json:"clientKey"`
+	ServerName         string `json:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"`
+}
+
+// Config builds a *tls.Config from the TLS settings.  The settings must already be resolved,
+// such that the certificate and key fields contain PEM data rather than templates.
+// A nil *TLSInfo returns a nil config, which uses the default TLS settings.
+func (t *TLSInfo) Config() (*tls.Config, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	for _, v := range []string{t.CACert, t.ClientCert, t.ClientKey, t.ServerName} {
+		if hasTemplate(v) {
+			return nil, errors.New("tls settings must be resolved before building a TLS configuration")
+		}
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: t.ServerName,
+		// Only intended for development.  It is rejected by validation when the production policy is active.
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if t.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(t.CACert)) {
+			return nil, errors.New("failed to parse tls caCert: no PEM certificates found")
+		}
+		cfg.RootCAs = pool
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(t.ClientCert), []byte(t.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func (t *TLSInfo) variables() []string {
+	var results []string
+	for _, v := range t.templatedValues() {
+		results = append(results, extractVariables(v)...)
+	}
+	return results
+}
+
+// templatedValues returns the values that may contain templates, in the order their variables are reported.
+func (t *TLSInfo) templatedValues() []string {
+	if t == nil {
+		return nil
+	}
+	return []string{t.CACert, t.ClientCert, t.ClientKey}
+}
+
+func (t *TLSInfo) clone() *TLSInfo {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+// redacted returns a copy of the TLS settings, with the client key masked.
+func (t *TLSInfo) redacted() *TLSInfo {
+	if t == nil {
+		return nil
+	}
+	r := *t
+	r.ClientKey = redactValue(r.ClientKey)
+	return &r
+}
+
+// secretFields returns the client key, which is the only TLS setting that holds a secret.
+func (t *TLSInfo) secretFields() []secretField {
+	if t == nil {
+		return nil
+	}
+	return []secretField{{[]string{"tls", "clientKey"}, t.ClientKey, "client key", "client key"}}
+}
+
+func (t *TLSInfo) hashFields() []hashField {
+	if t == nil {
+		return nil
+	}
+
+	return []hashField{
+		{"tls.caCert", t.CACert, legacyExtension},
+		{"tls.clientCert", t.ClientCert, legacyExtension},
+		{"tls.clientKey", t.ClientKey, legacyExtension},
+		{"tls.serverName", t.ServerName, legacyExtension},
+		{"tls.insecureSkipVerify", t.InsecureSkipVerify, legacyExtension},
+	}
+}
+
+func (t *TLSInfo) validate(path string, opts ValidationOptions) []Diagnostic {
+	if t == nil || !t.InsecureSkipVerify {
+		return nil
+	}
+
+	d := Diagnostic{
+		Severity: SeverityWarning,
+		Path:     path + "/insecureSkipVerify",
+		Message:  "insecureSkipVerify disables certificate verification, and should only be used for development",
+		Rule:     PolicyRuleInsecureSkipVerify,
+	}
+	if opts.Production {
+		d.Severity = SeverityError
+		d.Message = "insecureSkipVerify disables certificate verification, and is not allowed in production"
+	}
+	return []Diagnostic{d}
+}
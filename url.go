@@ -0,0 +1,166 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrNoHostURL            = errors.New("host has no endpoint or baseURL")
+	ErrPathWithEndpoint     = errors.New("a path cannot be applied to an endpoint, use baseURL instead")
+	ErrMissingTrailingSlash = errors.New("baseURL must end with a trailing slash")
+	ErrInvalidPath          = errors.New("path must be relative, without a scheme, host, query, or fragment")
+	ErrPathTraversal        = errors.New("path must not escape the baseURL")
+	ErrUnresolvedTemplate   = errors.New("query parameters must be resolved before building a URL")
+	ErrModelNotFound        = errors.New("model not found")
+	ErrHypermodeHostedModel = errors.New("model is hosted by hypermode, and has no URL")
+	ErrHostNotFound         = errors.New("host not found")
+	ErrNotHTTPHost          = errors.New("host is not an http host")
+	ErrHostTypeMismatch     = errors.New("host is not of the requested type")
+)
+
+// URLError is returned when a URL can't be built for a host or model.
+// Use errors.Is to check the underlying reason.
+type URLError struct {
+	// Model is set instead of Host when the model itself is not found.
+	Model string
+	Host  string
+	Path  string
+	Err   error
+}
+
+func (e *URLError) Error() string {
+	if e.Host == "" && e.Model != "" {
+		return fmt.Sprintf("failed to build url for model [%s]: %v", e.Model, e.Err)
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("failed to build url for host [%s]: %v", e.Host, e.Err)
+	}
+	return fmt.Sprintf("failed to build url for host [%s] and path [%s]: %v", e.Host, e.Path, e.Err)
+}
+
+func (e *URLError) Unwrap() error {
+	return e.Err
+}
+
+// BuildURL returns the URL for a request to the host.  If path is empty, the endpoint is used, or the baseURL if there
+// is no endpoint.  Otherwise, the path is applied to the baseURL, which must end with a trailing slash.  The path must
+// be relative, and must not escape the baseURL.  The host's query parameters are applied, followed by the given query,
+// which takes precedence.  The host's query parameters must already be resolved.
+func (h HTTPHostInfo) BuildURL(path string, query url.Values) (string, error) {
+	u, err := h.buildURL(path, query)
+	if err != nil {
+		return "", &URLError{Host: h.Name, Path: path, Err: err}
+	}
+	return u.String(), nil
+}
+
+func (h HTTPHostInfo) buildURL(path string, query url.Values) (*url.URL, error) {
+	var u *url.URL
+	switch {
+	case path == "" && h.Endpoint != "":
+		endpoint, err := url.Parse(h.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		u = endpoint
+	case h.BaseURL == "" && h.Endpoint != "":
+		return nil, ErrPathWithEndpoint
+	case h.BaseURL == "":
+		return nil, ErrNoHostURL
+	default:
+		base, err := url.Parse(h.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		u, err = resolvePath(base, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := h.EffectiveQueryParameters()
+	if len(params) == 0 && len(query) == 0 {
+		return u, nil
+	}
+
+	q := u.Query()
+	for k, v := range params {
+		if hasTemplate(k) || hasTemplate(v) {
+			return nil, ErrUnresolvedTemplate
+		}
+		q.Set(k, v)
+	}
+	for k, v := range query {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	return u, nil
+}
+
+func resolvePath(base *url.URL, path string) (*url.URL, error) {
+	if path == "" {
+		return base, nil
+	}
+
+	if !strings.HasSuffix(base.Path, "/") {
+		return nil, ErrMissingTrailingSlash
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPath, err)
+	}
+	if ref.IsAbs() || ref.Host != "" || ref.RawQuery != "" || ref.Fragment != "" || strings.HasPrefix(ref.Path, "/") {
+		return nil, ErrInvalidPath
+	}
+
+	u := base.ResolveReference(ref)
+	if !strings.HasPrefix(u.Path, base.Path) {
+		return nil, ErrPathTraversal
+	}
+
+	// Percent-encoded dot segments are not resolved above, but may be decoded and resolved by the server.
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment == ".." {
+			return nil, ErrPathTraversal
+		}
+	}
+
+	// The base query is not retained by ResolveReference.
+	u.RawQuery = base.RawQuery
+	return u, nil
+}
+
+// ModelURL returns the URL for requests to the model, by applying the model's path to its host.
+// Hosts of other types that are based on an http host, such as graphql hosts, are resolved through it.
+// The host's query parameters must already be resolved.
+func (m *HypermodeManifest) ModelURL(modelName string) (string, error) {
+	model, ok := m.Models[modelName]
+	if !ok {
+		return "", &URLError{Model: modelName, Err: ErrModelNotFound}
+	}
+
+	if model.Host == "hypermode" {
+		return "", &URLError{Host: model.Host, Path: model.Path, Err: ErrHypermodeHostedModel}
+	}
+
+	host, ok := m.Hosts[model.Host]
+	if !ok {
+		return "", &URLError{Host: model.Host, Path: model.Path, Err: ErrHostNotFound}
+	}
+
+	h, ok := asBuiltin(host).httpHost()
+	if !ok {
+		return "", &URLError{Host: model.Host, Path: model.Path, Err: ErrNotHTTPHost}
+	}
+
+	return h.BuildURL(model.Path, nil)
+}
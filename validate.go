@@ -0,0 +1,106 @@
+/*
+ * Copyright 2024 Hypermode, Inc.
+ */
+
+package manifest
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ValidationOptions controls the checks that are made in addition to the JSON schema.
+type ValidationOptions struct {
+	// Production enables the production policy, which rejects settings that are only
+	// intended for development, such as disabling TLS certificate verification, and
+	// reports hard-coded secrets as errors instead of warnings.
+	Production bool
+
+	// Policy, if set, is checked with CheckPolicy, and any violations are reported as error diagnostics.
+	Policy *Policy
+}
+
+// ValidateManifestWithOptions validates the manifest against the JSON schema, and then checks the
+// parsed manifest for problems that the schema can't express.  An error is returned if the manifest
+// doesn't match the schema or can't be parsed.  Otherwise, all findings are returned as diagnostics.
+func ValidateManifestWithOptions(content []byte, opts ValidationOptions) ([]Diagnostic, error) {
+	if err := validateSchema(content); err != nil {
+		return nil, err
+	}
+
+	m, err := ReadManifest(content)
+	if err != nil {
+		return nil, err
+	}
+
+	diags := m.validate(opts)
+	if opts.Policy != nil {
+		violations := CheckPolicy(m, *opts.Policy)
+		policyDiags := make([]Diagnostic, len(violations))
+		for i, v := range violations {
+			policyDiags[i] = v.Diagnostic()
+		}
+		diags = mergeDiagnostics(diags, policyDiags)
+	}
+
+	return diags, nil
+}
+
+// mergeDiagnostics appends the policy diagnostics to the validation diagnostics.  A policy diagnostic with the
+// same path and rule as a validation diagnostic reports the same problem, so only the more severe of the two is kept.
+func mergeDiagnostics(diags, policyDiags []Diagnostic) []Diagnostic {
+	n := len(diags)
+	for _, d := range policyDiags {
+		i := slices.IndexFunc(diags[:n], func(existing Diagnostic) bool {
+			return existing.Path == d.Path && existing.Rule == d.Rule
+		})
+		switch {
+		case i < 0:
+			diags = append(diags, d)
+		case d.Severity == SeverityError && diags[i].Severity != SeverityError:
+			diags[i] = d
+		}
+	}
+	return diags
+}
+
+func (m *HypermodeManifest) validate(opts ValidationOptions) []Diagnostic {
+	var diags []Diagnostic
+	for _, name := range sortedKeys(m.Hosts) {
+		diags = append(diags, asBuiltin(m.Hosts[name]).validate(opts)...)
+	}
+
+	for _, name := range sortedKeys(m.Models) {
+		model := m.Models[name]
+		if _, ok := m.Hosts[model.Host].(OpenAIHostInfo); ok && model.SourceModel == "" {
+			diags = append(diags, Diagnostic{
+				Severity: SeverityError,
+				Path:     jsonPointer("models", name, "sourceModel"),
+				Message:  "sourceModel is required for models that use an openai host, since it is sent as the model name",
+			})
+		}
+	}
+	return diags
+}
+
+// hostsEqual reports whether the hosts have the same type and settings, by comparing their canonical hashes,
+// in which unset and empty values are equivalent.
+func hostsEqual(a, b HostInfo) bool {
+	return b != nil && a.HostType() == b.HostType() &&
+		a.HashWith(HashVersionCanonical) == b.HashWith(HashVersionCanonical)
+}
+
+// validateProjectPath returns a message describing why the path of a local file is not allowed, or an empty string.
+// The path must be relative to the project, and must not escape it.  Both slashes and backslashes are
+// treated as separators, so that the result doesn't depend on the operating system.
+func validateProjectPath(field, path string) string {
+	p := strings.ReplaceAll(path, "\\", "/")
+	if strings.HasPrefix(p, "/") || filepath.IsAbs(path) || filepath.VolumeName(path) != "" || (len(p) > 1 && p[1] == ':') {
+		return field + " must be relative to the project"
+	}
+	if clean := filepath.ToSlash(filepath.Clean(p)); clean == ".." || strings.HasPrefix(clean, "../") {
+		return field + " must not escape the project"
+	}
+	return ""
+}